@@ -0,0 +1,125 @@
+package flagit
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Runner is implemented by command structs that can be executed by Execute.
+// The positional arguments that are not assigned to any field are passed to the Run method.
+type Runner interface {
+	Run(args []string) error
+}
+
+// command is a subcommand defined by a struct field with the cmd tag.
+type command struct {
+	name  string
+	field reflect.Value
+}
+
+// value returns the struct value of the command.
+// If the command field is a pointer, a new struct is allocated when the pointer is nil.
+func (c *command) value() reflect.Value {
+	if c.field.Kind() == reflect.Ptr {
+		if c.field.IsNil() {
+			c.field.Set(reflect.New(c.field.Type().Elem()))
+		}
+		return c.field.Elem()
+	}
+
+	return c.field
+}
+
+func isCommandSupported(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return isNestedStruct(t)
+}
+
+func iterateOnCommands(vStruct reflect.Value, continueOnError bool, handle func(c *command) error) error {
+	// Iterate over struct fields
+	for i := 0; i < vStruct.NumField(); i++ {
+		v := vStruct.Field(i)
+		t := v.Type()
+		f := vStruct.Type().Field(i)
+
+		// `cmd:"..."`
+		name := f.Tag.Get(cmdTag)
+
+		// Recursively, iterate on nested structs that are not commands
+		if name == "" && isNestedStruct(t) {
+			if err := iterateOnCommands(v, continueOnError, handle); err != nil {
+				return err
+			}
+		}
+
+		if name == "" {
+			continue
+		}
+
+		if !v.CanSet() || !isCommandSupported(t) {
+			if continueOnError {
+				continue
+			}
+			return fmt.Errorf("invalid command for field %s: %s", f.Name, t)
+		}
+
+		if !flagNameRE.MatchString(name) {
+			if continueOnError {
+				continue
+			}
+			return fmt.Errorf("invalid command name: %s", name)
+		}
+
+		err := handle(&command{
+			name:  name,
+			field: v,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commandPath returns the full name of a selected command (i.e. "remote add").
+func commandPath(cmds []*command) string {
+	names := make([]string, len(cmds))
+	for i, c := range cmds {
+		names[i] = c.name
+	}
+
+	return strings.Join(names, " ")
+}
+
+// Execute accepts the pointer to a struct type and populates it from the given arguments similar to PopulateArgs.
+// The struct fields with the cmd tag define subcommands and each subcommand is a struct (or a pointer to a struct) with its own flags.
+// The first positional argument selects a subcommand and the flags of the selected subcommand are populated in addition to the global flags.
+// Pointer subcommands are only allocated when they are selected.
+//
+// Execute returns the full name of the selected command (an empty string if no subcommand is selected).
+// If the selected command implements the Runner interface, its Run method is called with the remaining positional arguments.
+func Execute(s interface{}, args []string, continueOnError bool, opts ...Option) (string, error) {
+	cmds, args, err := populate(s, args, continueOnError, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	name := commandPath(cmds)
+
+	selected := s
+	if len(cmds) > 0 {
+		selected = cmds[len(cmds)-1].value().Addr().Interface()
+	}
+
+	if r, ok := selected.(Runner); ok {
+		return name, r.Run(args)
+	}
+
+	return name, nil
+}
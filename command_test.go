@@ -0,0 +1,381 @@
+package flagit
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	deployCommand struct {
+		Env    string `flag:"env"`
+		DryRun bool   `flag:"dry-run" short:"n"`
+		Target string `arg:"0,required"`
+	}
+
+	remoteAddCommand struct {
+		Fetch bool   `flag:"fetch" short:"f"`
+		Name  string `arg:"0,required"`
+		URL   string `arg:"1,required"`
+	}
+
+	remoteCommand struct {
+		Verbose bool              `flag:"verbose" short:"v"`
+		Add     *remoteAddCommand `cmd:"add"`
+		Remove  struct {
+			Name string `arg:"0"`
+		} `cmd:"remove"`
+	}
+
+	app struct {
+		Debug  bool           `flag:"debug"`
+		Deploy deployCommand  `cmd:"deploy"`
+		Remote *remoteCommand `cmd:"remote"`
+	}
+)
+
+type runnable struct {
+	Verbose bool `flag:"verbose"`
+	args    []string
+	err     error
+}
+
+func (r *runnable) Run(args []string) error {
+	r.args = args
+	return r.err
+}
+
+type runnableApp struct {
+	Run runnable `cmd:"run"`
+	Nop struct{} `cmd:"nop"`
+}
+
+func TestCommandValue(t *testing.T) {
+	a := &app{}
+	v := reflect.ValueOf(a).Elem()
+
+	deploy := &command{name: "deploy", field: v.FieldByName("Deploy")}
+	assert.Equal(t, v.FieldByName("Deploy"), deploy.value())
+
+	remote := &command{name: "remote", field: v.FieldByName("Remote")}
+	assert.Nil(t, a.Remote)
+	rv := remote.value()
+	assert.NotNil(t, a.Remote)
+	assert.Equal(t, reflect.ValueOf(a.Remote).Elem(), rv)
+
+	// The pointer should not be allocated again
+	prev := a.Remote
+	remote.value()
+	assert.True(t, prev == a.Remote)
+}
+
+func TestIsCommandSupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    interface{}
+		expected bool
+	}{
+		{"String", "", false},
+		{"URL", url.URL{}, false},
+		{"URLPointer", &url.URL{}, false},
+		{"Struct", deployCommand{}, true},
+		{"StructPointer", &deployCommand{}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			typ := reflect.TypeOf(tc.field)
+
+			assert.Equal(t, tc.expected, isCommandSupported(typ))
+		})
+	}
+}
+
+func TestIterateOnCommands(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		continueOnError bool
+		expectedError   error
+		expectedNames   []string
+	}{
+		{
+			name: "InvalidType",
+			s: &struct {
+				Deploy string `cmd:"deploy"`
+			}{},
+			expectedError: errors.New("invalid command for field Deploy: string"),
+			expectedNames: []string{},
+		},
+		{
+			name: "Unexported",
+			s: &struct {
+				deploy deployCommand `cmd:"deploy"`
+			}{},
+			expectedError: errors.New("invalid command for field deploy: flagit.deployCommand"),
+			expectedNames: []string{},
+		},
+		{
+			name: "InvalidName",
+			s: &struct {
+				Deploy deployCommand `cmd:"deploy now"`
+			}{},
+			expectedError: errors.New("invalid command name: deploy now"),
+			expectedNames: []string{},
+		},
+		{
+			name: "ContinueOnError",
+			s: &struct {
+				Deploy string        `cmd:"deploy"`
+				Build  deployCommand `cmd:"build now"`
+			}{},
+			continueOnError: true,
+			expectedError:   nil,
+			expectedNames:   []string{},
+		},
+		{
+			name: "OK",
+			s: &struct {
+				Debug  bool           `flag:"debug"`
+				Deploy deployCommand  `cmd:"deploy"`
+				Remote *remoteCommand `cmd:"remote"`
+				Group  struct {
+					Build struct{} `cmd:"build"`
+				}
+			}{},
+			expectedError: nil,
+			expectedNames: []string{"deploy", "remote", "build"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			names := []string{}
+
+			vStruct, err := validateStruct(tc.s)
+			assert.NoError(t, err)
+
+			err = iterateOnCommands(vStruct, tc.continueOnError, func(c *command) error {
+				names = append(names, c.name)
+				return nil
+			})
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedNames, names)
+		})
+	}
+}
+
+func TestCommandPath(t *testing.T) {
+	assert.Equal(t, "", commandPath(nil))
+	assert.Equal(t, "remote add", commandPath([]*command{{name: "remote"}, {name: "add"}}))
+}
+
+func TestPopulateArgsCommands(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expectedArgs  []string
+		expected      *app
+	}{
+		{
+			name:         "NoCommand",
+			args:         []string{"--debug"},
+			expectedArgs: []string{},
+			expected:     &app{Debug: true},
+		},
+		{
+			name:         "Deploy",
+			args:         []string{"--debug", "deploy", "-n", "--env", "prod", "api", "extra"},
+			expectedArgs: []string{"extra"},
+			expected: &app{
+				Debug: true,
+				Deploy: deployCommand{
+					Env:    "prod",
+					DryRun: true,
+					Target: "api",
+				},
+			},
+		},
+		{
+			name:         "GlobalFlagAfterCommand",
+			args:         []string{"deploy", "api", "--debug"},
+			expectedArgs: []string{},
+			expected: &app{
+				Debug: true,
+				Deploy: deployCommand{
+					Target: "api",
+				},
+			},
+		},
+		{
+			name:         "NestedCommand",
+			args:         []string{"remote", "-v", "add", "-f", "origin", "https://example.com/repo.git"},
+			expectedArgs: []string{},
+			expected: &app{
+				Remote: &remoteCommand{
+					Verbose: true,
+					Add: &remoteAddCommand{
+						Fetch: true,
+						Name:  "origin",
+						URL:   "https://example.com/repo.git",
+					},
+				},
+			},
+		},
+		{
+			name:         "CommandNameAfterTerminator",
+			args:         []string{"--", "deploy"},
+			expectedArgs: []string{"deploy"},
+			expected:     &app{},
+		},
+		{
+			name:          "UnknownCommand",
+			args:          []string{"--debug", "destroy"},
+			expectedError: "unknown command: destroy",
+		},
+		{
+			name:          "CommandFlagBeforeCommand",
+			args:          []string{"--env=prod", "deploy", "api"},
+			expectedError: "flag provided but not defined: --env",
+		},
+		{
+			name:          "SiblingCommandFlag",
+			args:          []string{"remote", "add", "--env=prod"},
+			expectedError: "flag provided but not defined: --env",
+		},
+		{
+			name:          "MissingCommandArgument",
+			args:          []string{"deploy"},
+			expectedError: "missing required argument: Target (position 0)",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &app{}
+			args, err := PopulateArgs(s, tc.args, false)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedArgs, args)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.Nil(t, args)
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		args            []string
+		continueOnError bool
+		runError        error
+		expectedError   error
+		expectedCommand string
+		expectedRunArgs []string
+	}{
+		{
+			name:          "NonPointer",
+			s:             app{},
+			args:          []string{},
+			expectedError: errors.New("non-pointer type: you should pass a pointer to a struct type"),
+		},
+		{
+			name: "DuplicateCommand",
+			s: &struct {
+				A struct{} `cmd:"deploy"`
+				B struct{} `cmd:"deploy"`
+			}{},
+			args:          []string{},
+			expectedError: errors.New("command already registered: deploy"),
+		},
+		{
+			name:          "ParseError",
+			s:             &app{},
+			args:          []string{"unknown"},
+			expectedError: errors.New("unknown command: unknown"),
+		},
+		{
+			name:            "ContinueOnError",
+			s:               &app{},
+			args:            []string{"unknown", "deploy"},
+			continueOnError: true,
+			expectedCommand: "",
+		},
+		{
+			name:            "NoCommand",
+			s:               &app{},
+			args:            []string{},
+			expectedCommand: "",
+		},
+		{
+			name:            "NestedCommand",
+			s:               &app{},
+			args:            []string{"remote", "add", "origin", "https://example.com/repo.git"},
+			expectedCommand: "remote add",
+		},
+		{
+			name:            "NotRunner",
+			s:               &runnableApp{},
+			args:            []string{"nop"},
+			expectedCommand: "nop",
+		},
+		{
+			name:            "Runner",
+			s:               &runnableApp{},
+			args:            []string{"run", "--verbose", "a", "b"},
+			expectedCommand: "run",
+			expectedRunArgs: []string{"a", "b"},
+		},
+		{
+			name:            "RunnerError",
+			s:               &runnableApp{},
+			args:            []string{"run"},
+			runError:        errors.New("run error"),
+			expectedError:   errors.New("run error"),
+			expectedCommand: "run",
+			expectedRunArgs: []string{},
+		},
+		{
+			name:            "RootRunner",
+			s:               &runnable{},
+			args:            []string{"a"},
+			expectedCommand: "",
+			expectedRunArgs: []string{"a"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var r *runnable
+			switch s := tc.s.(type) {
+			case *runnableApp:
+				r = &s.Run
+			case *runnable:
+				r = s
+			}
+
+			if r != nil {
+				r.err = tc.runError
+			}
+
+			cmd, err := Execute(tc.s, tc.args, tc.continueOnError)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedCommand, cmd)
+
+			if r != nil {
+				assert.Equal(t, tc.expectedRunArgs, r.args)
+			}
+		})
+	}
+}
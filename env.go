@@ -0,0 +1,38 @@
+package flagit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+var envNameReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// envName derives the name of an environment variable from a flag name.
+func envName(prefix, flag string) string {
+	name := strings.ToUpper(envNameReplacer.Replace(flag))
+	if prefix != "" {
+		name = prefix + "_" + name
+	}
+
+	return name
+}
+
+// setEnv reads the value of the field from its environment variable.
+// It returns false if the field does not have an environment variable or the environment variable is not set.
+func (f fieldInfo) setEnv() (bool, error) {
+	if f.env == "" {
+		return false, nil
+	}
+
+	val, ok := os.LookupEnv(f.env)
+	if !ok {
+		return false, nil
+	}
+
+	if _, err := f.setValue(val, false); err != nil {
+		return false, fmt.Errorf("invalid value %q for environment variable %s: %s", val, f.env, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,72 @@
+package flagit
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		flag     string
+		expected string
+	}{
+		{"NoPrefix", "", "port", "PORT"},
+		{"Dash", "", "log-level", "LOG_LEVEL"},
+		{"Dot", "APP", "log.level", "APP_LOG_LEVEL"},
+		{"Nested", "APP", "db.read-timeout", "APP_DB_READ_TIMEOUT"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, envName(tc.prefix, tc.flag))
+		})
+	}
+}
+
+func TestFieldInfoSetEnv(t *testing.T) {
+	os.Setenv("FLAGIT_TEST_PORT", "8080")
+	defer os.Unsetenv("FLAGIT_TEST_PORT")
+
+	os.Setenv("FLAGIT_TEST_INVALID", "invalid")
+	defer os.Unsetenv("FLAGIT_TEST_INVALID")
+
+	tests := []struct {
+		name          string
+		env           string
+		value         int
+		expectedOK    bool
+		expectedError string
+		expectedValue int
+	}{
+		{"NoEnv", "", 80, false, "", 80},
+		{"NotSet", "FLAGIT_TEST_UNSET", 80, false, "", 80},
+		{"Set", "FLAGIT_TEST_PORT", 80, true, "", 8080},
+		{"Invalid", "FLAGIT_TEST_INVALID", 80, false, `invalid value "invalid" for environment variable FLAGIT_TEST_INVALID: strconv.ParseInt: parsing "invalid": invalid syntax`, 80},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			val := tc.value
+			f := fieldInfo{
+				value: reflect.ValueOf(&val).Elem(),
+				env:   tc.env,
+			}
+
+			ok, err := f.setEnv()
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedValue, val)
+		})
+	}
+}
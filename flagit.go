@@ -10,50 +10,351 @@ import (
 	"os"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/moorara/flagit/set"
 )
 
 const (
-	flagTag = "flag"
-	sepTag  = "sep"
+	flagTag      = "flag"
+	argTag       = "arg"
+	cmdTag       = "cmd"
+	shortTag     = "short"
+	sepTag       = "sep"
+	kvSepTag     = "kvsep"
+	modeTag      = "mode"
+	negatableTag = "negatable"
+	envTag       = "env"
+	defaultTag   = "default"
+	requiredTag  = "required"
+	layoutTag    = "layout"
+	tzTag        = "tz"
+	unitTag      = "unit"
+	baseTag      = "base"
+	enumTag      = "enum"
+)
+
+const (
+	// modeAppend is the mode for repeatable flags that accumulate into slice fields.
+	modeAppend = "append"
+	// modeCount is the mode for repeatable flags that increment integer fields.
+	modeCount = "count"
 )
 
 var (
-	flagNameRE = regexp.MustCompile(`^[A-Za-z]([0-9A-Za-z-.]*[0-9A-Za-z])?$`)
-	flagArgRE  = regexp.MustCompile("^-{1,2}[A-Za-z]([0-9A-Za-z-.]*[0-9A-Za-z])?")
+	flagNameRE  = regexp.MustCompile(`^[A-Za-z]([0-9A-Za-z-.]*[0-9A-Za-z])?$`)
+	shortNameRE = regexp.MustCompile(`^[A-Za-z]$`)
 )
 
+type options struct {
+	negatable     bool
+	autoEnv       bool
+	envPrefix     string
+	location      *time.Location
+	report        *Report
+	ignoreUnknown bool
+}
+
+// Option configures the behavior of Populate, PopulateArgs, and RegisterFlags.
+type Option func(*options)
+
+// Negatable generates a -no-<flag> counterpart for all boolean flags that do not have the negatable tag.
+// Negation can also be enabled for individual boolean fields using the negatable tag (`negatable:"true"`),
+// and the fields with `negatable:"false"` are never negatable.
+func Negatable() Option {
+	return func(o *options) {
+		o.negatable = true
+	}
+}
+
+// EnvPrefix derives an environment variable for all flags that do not have the env tag.
+// The name of the environment variable is the flag name in upper case with dashes and dots replaced by underscores,
+// and prefixed by the given prefix and an underscore (the prefix can be empty).
+// For example, with the APP prefix, the environment variable for the log.level flag is APP_LOG_LEVEL.
+func EnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.autoEnv = true
+		o.envPrefix = prefix
+	}
+}
+
+// Location sets the location for parsing the time values of all flags that do not have the tz tag.
+// The location is used for the values without time zone information and for Unix times.
+// The default location is UTC.
+func Location(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// ignoreUnknown skips the flags that are not defined by the struct.
+// It is used by Populate, since os.Args can also have flags for other consumers (such as the -test.* flags under go test).
+func ignoreUnknown() Option {
+	return func(o *options) {
+		o.ignoreUnknown = true
+	}
+}
+
+func newOptions(opts ...Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// field applies the global options to a field.
+func (o options) field(f fieldInfo) fieldInfo {
+	if o.negatable && !f.hasNegatable && f.isBool() {
+		f.negatable = true
+	}
+
+	if o.autoEnv && f.env == "" {
+		f.env = envName(o.envPrefix, f.flag)
+	}
+
+	if o.location != nil && f.location == nil {
+		f.location = o.location
+	}
+
+	return f
+}
+
 type fieldInfo struct {
-	value reflect.Value
-	name  string
-	flag  string
-	help  string
-	sep   string
+	value        reflect.Value
+	name         string
+	flag         string
+	short        string
+	help         string
+	sep          string
+	mode         string
+	negatable    bool
+	hasNegatable bool
+	env          string
+	def          string
+	required     bool
+	rules        []rule
+	valueTags
+}
+
+// negation returns the name of the negative form of a negatable boolean flag.
+func (f fieldInfo) negation() string {
+	return "no-" + f.flag
+}
+
+// isBool determines whether or not the field is a boolean flag.
+// Boolean flags can only take a value through the equal sign (-flag=false).
+func (f fieldInfo) isBool() bool {
+	t := f.value.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Bool
+}
+
+// isBoolFlag determines whether or not the field is a flag that does not need a value.
+// Similar to the flag package, these flags are boolean flags and counter flags.
+func (f fieldInfo) isBoolFlag() bool {
+	return f.isBool() || f.mode == modeCount
+}
+
+// isTime determines whether or not the field is a time.Time, a pointer to time.Time, or a slice of time.Time.
+func (f fieldInfo) isTime() bool {
+	t := f.value.Type()
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	return isTimeType(t)
+}
+
+// setValue parses a string value and assigns it to the field.
+// For repeatable flags, the values of the repeated occurrences are appended to the slice instead of replacing it.
+// For map fields, the entries of the repeated occurrences are merged into the map.
+// For counter flags, every occurrence without a value increments the field and the first one resets it.
+func (f fieldInfo) setValue(val string, repeated bool) (bool, error) {
+	switch {
+	case f.mode == modeAppend && repeated:
+		return set.Append(f.value, f.sep, val, f.setOptions()...)
+	case f.value.Kind() == reflect.Map && repeated:
+		return set.Merge(f.value, f.sep, val, f.setOptions()...)
+	case f.mode == modeCount && val == "true":
+		return increment(f.value, repeated)
+	}
+
+	return set.Value(f.value, f.sep, val, f.setOptions()...)
+}
+
+// setDefault parses the value of the default tag and assigns it to the field.
+// The default value is only assigned if the field has the zero value, so the values set explicitly take precedence.
+func (f fieldInfo) setDefault() (bool, error) {
+	if f.def == "" || !f.value.IsZero() {
+		return false, nil
+	}
+
+	return f.setValue(f.def, false)
+}
+
+// increment adds one to an integer value.
+// If the flag is not repeated yet, the value is set to one.
+func increment(v reflect.Value, repeated bool) (bool, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64 = 1
+		if repeated {
+			i = v.Int() + 1
+		}
+		if v.OverflowInt(i) {
+			return false, fmt.Errorf("value out of range: %d", i)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64 = 1
+		if repeated {
+			u = v.Uint() + 1
+		}
+		if u == 0 || v.OverflowUint(u) {
+			return false, fmt.Errorf("value out of range: %d", u)
+		}
+		v.SetUint(u)
+	default:
+		return false, fmt.Errorf("unsupported kind: %s", v.Kind())
+	}
+
+	return true, nil
 }
 
 // flagValue implements the flag.Value interface.
 type flagValue struct {
 	continueOnError bool
-	value           reflect.Value
-	sep             string
+	field           fieldInfo
+	seen            bool
+	report          *Report
 }
 
 // String is called for getting and printing the default value.
 // Default value is already included in the usage string.
-func (v flagValue) String() string {
+func (v *flagValue) String() string {
 	return ""
 }
 
-func (v flagValue) Set(val string) error {
-	if _, err := set.Value(v.value, v.sep, val); err != nil {
+// IsBoolFlag lets the flag package know that counter flags do not need a value.
+func (v *flagValue) IsBoolFlag() bool {
+	return v.field.mode == modeCount
+}
+
+func (v *flagValue) Set(val string) error {
+	repeated := v.seen
+	v.seen = true
+
+	if _, err := v.field.setValue(val, repeated); err != nil {
 		if v.continueOnError {
 			return nil
 		}
 		return err
 	}
 
+	if err := v.field.validate(); err != nil && !v.continueOnError {
+		return err
+	}
+
+	v.report.set(v.field, OriginFlag, val, repeated)
+
+	return nil
+}
+
+// errParse is the same error returned by the flag package for invalid boolean values.
+var errParse = errors.New("parse error")
+
+// boolValue implements the flag.Value interface for boolean flags similar to the flag package.
+// Unlike the flag package, the values are parsed using the boolean vocabulary of the set package (see set.SetBoolVocabulary).
+type boolValue struct {
+	field  fieldInfo
+	report *Report
+}
+
+// String is called for getting and printing the default value.
+func (v *boolValue) String() string {
+	// The zero value is used by the flag package for checking whether or not the default value is the zero value
+	if !v.field.value.IsValid() {
+		return "false"
+	}
+	return strconv.FormatBool(v.field.value.Bool())
+}
+
+// IsBoolFlag lets the flag package know that this flag does not need a value.
+func (v *boolValue) IsBoolFlag() bool {
+	return true
+}
+
+func (v *boolValue) Set(val string) error {
+	b, err := set.ParseBool(val)
+	if err != nil {
+		return errParse
+	}
+
+	v.field.value.SetBool(b)
+	v.report.set(v.field, OriginFlag, val, false)
+
+	return nil
+}
+
+// negatableValue implements the flag.Value interface for the positive and negative forms of a negatable boolean flag.
+type negatableValue struct {
+	continueOnError bool
+	field           fieldInfo
+	name            string
+	negated         bool
+	form            *string // the form that is already set and shared between the positive and negative forms
+	report          *Report
+}
+
+// String is called for getting and printing the default value.
+// Default value is already included in the usage string.
+func (v *negatableValue) String() string {
+	return ""
+}
+
+// IsBoolFlag lets the flag package know that this flag does not need a value.
+func (v *negatableValue) IsBoolFlag() bool {
+	return true
+}
+
+func (v *negatableValue) Set(val string) error {
+	b, err := set.ParseBool(val)
+	if err != nil {
+		if v.continueOnError {
+			return nil
+		}
+		return err
+	}
+
+	if *v.form != "" && *v.form != v.name {
+		if v.continueOnError {
+			return nil
+		}
+		return fmt.Errorf("conflicting flags: %s and %s", *v.form, v.name)
+	}
+	*v.form = v.name
+
+	if v.negated {
+		b = !b
+	}
+
+	val = strconv.FormatBool(b)
+	if _, err := v.field.setValue(val, false); err != nil {
+		if v.continueOnError {
+			return nil
+		}
+		return err
+	}
+
+	v.report.set(v.field, OriginFlag, val, false)
+
 	return nil
 }
 
@@ -79,7 +380,111 @@ func validateStruct(s interface{}) (reflect.Value, error) {
 
 func isStructSupported(t reflect.Type) bool {
 	return (t.PkgPath() == "net/url" && t.Name() == "URL") ||
-		(t.PkgPath() == "regexp" && t.Name() == "Regexp")
+		(t.PkgPath() == "regexp" && t.Name() == "Regexp") ||
+		(t.PkgPath() == "net" && t.Name() == "IPNet") ||
+		(t.PkgPath() == "net" && t.Name() == "TCPAddr") ||
+		isTimeType(t) ||
+		set.IsCustomType(t)
+}
+
+func isTimeType(t reflect.Type) bool {
+	return t.PkgPath() == "time" && t.Name() == "Time"
+}
+
+// isNetType determines whether or not a type is one of the types from the net package or a pointer to one of them.
+func isNetType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.PkgPath() == "net"
+}
+
+// isSingleValue determines whether or not a type is set from a single value regardless of its kind.
+// These are the custom types (such as net.IP) and net.HardwareAddr, which are slices of bytes.
+func isSingleValue(t reflect.Type) bool {
+	return set.IsCustomType(t) ||
+		(t.PkgPath() == "net" && t.Name() == "HardwareAddr")
+}
+
+// enumElem returns the type that the choices of an enum field are for.
+// The choices of slices and maps are for their elements.
+func enumElem(t reflect.Type) reflect.Type {
+	if !isSingleValue(t) && (t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// lookupEnum returns the choices registered for the type of a field (see set.RegisterEnum).
+func lookupEnum(t reflect.Type) []set.Choice {
+	choices, _ := set.LookupEnum(enumElem(t))
+	return choices
+}
+
+// validateEnum verifies that the choices of an enum can be used for a type.
+func validateEnum(t reflect.Type, choices []set.Choice, opts ...set.Option) error {
+	elem := enumElem(t)
+	if elem.Kind() == reflect.Bool {
+		return errors.New("boolean flags cannot have choices")
+	}
+
+	opts = append(opts, set.Enum(choices...))
+	for _, c := range choices {
+		if _, err := set.Value(reflect.New(elem).Elem(), "", c.Name, opts...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatEnum formats the value of an enum field by the names of its choices.
+// The values that are not any of the choices cannot be formatted.
+func formatEnum(f fieldInfo) (string, bool) {
+	if len(f.enum) == 0 {
+		return "", false
+	}
+
+	v := f.value
+
+	switch {
+	case v.Kind() == reflect.Map:
+		return "", false
+	case v.Kind() == reflect.Slice && !isSingleValue(v.Type()):
+		names := []string{}
+		for i := 0; i < v.Len(); i++ {
+			name, ok := enumName(v.Index(i), f)
+			if !ok {
+				return "", false
+			}
+			names = append(names, name)
+		}
+		return "[" + strings.Join(names, " ") + "]", true
+	}
+
+	return enumName(v, f)
+}
+
+// enumName returns the name of the choice of an enum field that a value is equal to.
+func enumName(v reflect.Value, f fieldInfo) (string, bool) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return "", false
+	}
+
+	for _, c := range f.enum {
+		tmp := reflect.New(v.Type()).Elem()
+		if _, err := set.Value(tmp, "", c.Name, f.setOptions()...); err == nil && reflect.DeepEqual(tmp.Interface(), v.Interface()) {
+			return c.Name, true
+		}
+	}
+
+	return "", false
 }
 
 func isNestedStruct(t reflect.Type) bool {
@@ -94,7 +499,28 @@ func isNestedStruct(t reflect.Type) bool {
 	return true
 }
 
+func isModeSupported(mode string, t reflect.Type) bool {
+	switch mode {
+	case modeAppend:
+		return t.Kind() == reflect.Slice && !isSingleValue(t)
+	case modeCount:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return true
+		}
+	}
+
+	return false
+}
+
 func isTypeSupported(t reflect.Type) bool {
+	// Custom types implementing encoding.TextUnmarshaler or flag.Value
+	if set.IsCustomType(t) {
+		return true
+	}
+
 	switch t.Kind() {
 	case reflect.String:
 		return true
@@ -110,32 +536,104 @@ func isTypeSupported(t reflect.Type) bool {
 		return isStructSupported(t)
 	case reflect.Ptr, reflect.Slice:
 		return isTypeSupported(t.Elem())
+	case reflect.Map:
+		return isScalarSupported(t.Key()) && t.Key().Comparable() && isScalarSupported(t.Elem())
 	default:
 		return false
 	}
 }
 
-func getFlagValue(flag string) string {
-	flagRegex := regexp.MustCompile("-{1,2}" + flag)
+// isScalarSupported determines whether or not a type is a supported type that holds a single value.
+// Only these types can be used as the keys and the elements of maps.
+func isScalarSupported(t reflect.Type) bool {
+	if isSingleValue(t) {
+		return true
+	}
 
-	for i, arg := range os.Args {
-		if flagRegex.MatchString(arg) {
-			if s := strings.Index(arg, "="); s > 0 {
-				return arg[s+1:]
-			}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return false
+	default:
+		return isTypeSupported(t)
+	}
+}
 
-			if i+1 < len(os.Args) {
-				if val := os.Args[i+1]; !flagArgRE.MatchString(val) {
-					return val
-				}
-			}
+// valueTags are the tags of a field for parsing its string values.
+type valueTags struct {
+	kvSep    string
+	layout   string
+	location *time.Location
+	unit     string
+	base     int
+	enum     []set.Choice
+}
+
+// setOptions returns the options for parsing the string values of the field.
+func (vt valueTags) setOptions() []set.Option {
+	return []set.Option{
+		set.KeyValueSeparator(vt.kvSep),
+		set.Layout(vt.layout),
+		set.Location(vt.location),
+		set.Unit(vt.unit),
+		set.Base(vt.base),
+		set.Enum(vt.enum...),
+	}
+}
 
-			// For boolean flags
-			return "true"
+// parseValueTags parses the kvsep, layout, tz, unit, base, and enum tags of a field of the given type.
+// The name identifies the field in the errors (flag <name> or argument <name>).
+// Without the enum tag, the choices registered for the type are used (see set.RegisterEnum).
+func parseValueTags(tag reflect.StructTag, t reflect.Type, name string) (valueTags, error) {
+	vt := valueTags{
+		kvSep:  tag.Get(kvSepTag),
+		layout: tag.Get(layoutTag),
+	}
+
+	// `kvsep:"..."`
+	if vt.kvSep == "" {
+		vt.kvSep = "="
+	}
+
+	// `unit:"..."`
+	vt.unit = tag.Get(unitTag)
+	if vt.unit != "" && !set.IsUnitSupported(vt.unit, t) {
+		return valueTags{}, fmt.Errorf("invalid unit for %s: %s", name, vt.unit)
+	}
+
+	// `base:"..."`
+	if val := tag.Get(baseTag); val != "" {
+		var err error
+		vt.base, err = strconv.Atoi(val)
+		// The digits of the values in a base could be mistaken for unit suffixes
+		if err != nil || !set.IsBaseSupported(vt.base, t) || vt.unit != "" {
+			return valueTags{}, fmt.Errorf("invalid base for %s: %s", name, val)
 		}
 	}
 
-	return ""
+	// `enum:"..."`
+	if val := tag.Get(enumTag); val != "" {
+		var err error
+		vt.enum, err = set.ParseChoices(val)
+		if err == nil {
+			err = validateEnum(t, vt.enum, set.Unit(vt.unit), set.Base(vt.base))
+		}
+		if err != nil {
+			return valueTags{}, fmt.Errorf("invalid enum for %s: %s", name, err)
+		}
+	} else {
+		vt.enum = lookupEnum(t)
+	}
+
+	// `tz:"..."`
+	if val := tag.Get(tzTag); val != "" {
+		var err error
+		vt.location, err = time.LoadLocation(val)
+		if err != nil {
+			return valueTags{}, fmt.Errorf("invalid tz for %s: %s", name, val)
+		}
+	}
+
+	return vt, nil
 }
 
 func iterateOnFields(prefix string, vStruct reflect.Value, continueOnError bool, handle func(f fieldInfo) error) error {
@@ -145,6 +643,11 @@ func iterateOnFields(prefix string, vStruct reflect.Value, continueOnError bool,
 		t := v.Type()                // reflect.Type        --> t.Kind(), t.PkgPath(), t.Name(), t.NumField()
 		f := vStruct.Type().Field(i) // reflect.StructField --> f.Name, f.Type.Name(), f.Type.Kind(), f.Tag.Get(tag)
 
+		// Subcommands are iterated separately
+		if f.Tag.Get(cmdTag) != "" {
+			continue
+		}
+
 		// Recursively, iterate on nested structs with flag tag
 		if isNestedStruct(t) {
 			newPrefix := prefix + f.Tag.Get(flagTag)
@@ -184,19 +687,107 @@ func iterateOnFields(prefix string, vStruct reflect.Value, continueOnError bool,
 			return fmt.Errorf("invalid flag name: %s", flagName)
 		}
 
+		// `short:"..."`
+		short := f.Tag.Get(shortTag)
+		if short != "" && !shortNameRE.MatchString(short) {
+			if continueOnError {
+				continue
+			}
+			return fmt.Errorf("invalid short flag name: %s", short)
+		}
+
+		// `negatable:"..."`
+		var negatable bool
+		if val := f.Tag.Get(negatableTag); val != "" {
+			var err error
+			negatable, err = strconv.ParseBool(val)
+			if err != nil || (negatable && !(fieldInfo{value: v}).isBool()) {
+				if continueOnError {
+					continue
+				}
+				return fmt.Errorf("invalid negatable for flag %s: %s", flagName, val)
+			}
+		}
+
 		// `sep:"..."`
 		sep := f.Tag.Get(sepTag)
 		if sep == "" {
 			sep = ","
 		}
 
-		err := handle(fieldInfo{
-			value: v,
-			name:  f.Name,
-			flag:  flagName,
-			help:  flagHelp,
-			sep:   sep,
-		})
+		// `mode:"..."`
+		mode := f.Tag.Get(modeTag)
+		if mode != "" && !isModeSupported(mode, t) {
+			if continueOnError {
+				continue
+			}
+			return fmt.Errorf("invalid mode for flag %s: %s", flagName, mode)
+		}
+
+		// `required:"..."`
+		var required bool
+		if val := f.Tag.Get(requiredTag); val != "" {
+			var err error
+			required, err = strconv.ParseBool(val)
+			if err != nil {
+				if continueOnError {
+					continue
+				}
+				return fmt.Errorf("invalid required for flag %s: %s", flagName, val)
+			}
+		}
+
+		// `kvsep:"..."`, `layout:"..."`, `tz:"..."`, `unit:"..."`, `base:"..."`, and `enum:"..."`
+		vt, err := parseValueTags(f.Tag, t, "flag "+flagName)
+		if err != nil {
+			if continueOnError {
+				continue
+			}
+			return err
+		}
+
+		// `min:"..."`, `max:"..."`, `oneof:"..."`, `pattern:"..."`, `minlen:"..."`, `maxlen:"..."`, and `nonempty:"..."`
+		rules, err := parseRules(f.Tag, t)
+		if err != nil {
+			if continueOnError {
+				continue
+			}
+			return fmt.Errorf("invalid validation rule for flag %s: %s", flagName, err)
+		}
+
+		// `env:"..."`
+		env := f.Tag.Get(envTag)
+
+		field := fieldInfo{
+			value:        v,
+			name:         f.Name,
+			flag:         flagName,
+			short:        short,
+			help:         flagHelp,
+			sep:          sep,
+			mode:         mode,
+			negatable:    negatable,
+			hasNegatable: f.Tag.Get(negatableTag) != "",
+			env:          env,
+			def:          f.Tag.Get(defaultTag),
+			required:     required,
+			rules:        rules,
+			valueTags:    vt,
+		}
+
+		// `default:"..."`
+		if field.def != "" {
+			tmp := field
+			tmp.value = reflect.New(t).Elem()
+			if _, err := tmp.setValue(tmp.def, false); err != nil {
+				if continueOnError {
+					continue
+				}
+				return fmt.Errorf("invalid default value for flag %s: %s", flagName, err)
+			}
+		}
+
+		err = handle(field)
 
 		if err != nil {
 			return err
@@ -208,43 +799,170 @@ func iterateOnFields(prefix string, vStruct reflect.Value, continueOnError bool,
 
 // Populate accepts the pointer to a struct type.
 // For those struct fields that have the flag tag, it will read values from command-line flags and parse them to the appropriate types.
+// The flags missing from the command-line are read from their environment variables if set (see EnvPrefix and the env tag).
+// Otherwise, the struct fields with the zero value are set to the value of their default tags.
+// Finally, the values are checked against the validation tags (see Validate).
 // This method does not use the built-in flag package for parsing and reading the flags.
-func Populate(s interface{}, continueOnError bool) error {
+// Use PopulateArgs if you need the positional arguments that are not consumed by any flag.
+// The flags that are not defined by the struct are ignored, since os.Args can also have flags for other consumers.
+func Populate(s interface{}, continueOnError bool, opts ...Option) error {
+	var args []string
+	if len(os.Args) > 1 {
+		args = os.Args[1:]
+	}
+
+	opts = append([]Option{ignoreUnknown()}, opts...)
+	_, err := PopulateArgs(s, args, continueOnError, opts...)
+	return err
+}
+
+// PopulateArgs is the same as Populate, but it reads the flags from the given arguments instead of os.Args.
+// The arguments should not include the program name (similar to os.Args[1:]).
+// Flags are read until the "--" terminator.
+// Unlike Populate, any flag that is not defined by the struct is an error if continueOnError is false.
+// The positional arguments are assigned to the struct fields that have the arg tag and the remaining ones are returned.
+func PopulateArgs(s interface{}, args []string, continueOnError bool, opts ...Option) ([]string, error) {
+	_, args, err := populate(s, args, continueOnError, opts...)
+	return args, err
+}
+
+// populate reads the flags and the positional arguments from args into the struct and the selected subcommands.
+// It returns the selected subcommands and the remaining positional arguments.
+func populate(s interface{}, args []string, continueOnError bool, opts ...Option) ([]*command, []string, error) {
 	v, err := validateStruct(s)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	return iterateOnFields("", v, continueOnError, func(f fieldInfo) error {
-		if val := getFlagValue(f.flag); val != "" {
-			if _, err := set.Value(f.value, f.sep, val); err != nil {
-				if continueOnError {
-					return nil
-				}
-				return err
-			}
+	p := newParser(continueOnError)
+	p.options = newOptions(opts...)
+
+	if err := p.addStruct(v); err != nil {
+		return nil, nil, err
+	}
+
+	positionals, seen, err := p.populate(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Read the flags missing from the command-line from the environment variables or the default tags
+	for _, f := range p.fields {
+		if seen[f.flag] {
+			continue
 		}
 
-		return nil
-	})
+		ok, err := f.setEnv()
+		if err != nil && !continueOnError {
+			return nil, nil, err
+		}
+
+		if ok {
+			// A value from the environment satisfies the required tag even if it is the zero value
+			seen[f.flag] = true
+			p.options.report.set(f, OriginEnv, os.Getenv(f.env), false)
+		} else if ok, _ := f.setDefault(); ok {
+			p.options.report.set(f, OriginDefault, f.def, false)
+		}
+	}
+
+	if !continueOnError {
+		if err := checkRequired(p.fields, seen); err != nil {
+			return nil, nil, err
+		}
+
+		if err := validateFields(p.fields); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Positional arguments belong to the selected command
+	if len(p.selected) > 0 {
+		v = p.selected[len(p.selected)-1].value()
+	}
+
+	positionals, err = bindArgs(v, positionals, continueOnError, p.options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return p.selected, positionals, nil
 }
 
 // RegisterFlags accepts a flag set and the pointer to a struct type.
 // For those struct fields that have the flag tag, it will register a flag on the given flag set.
 // The current values of the struct fields will be used as default values for the registered flags.
+// For the struct fields with the zero value, the value of the default tag will be used as the default value.
+// If the environment variable of a flag is set, its value will be used as the default value instead.
 // Once the Parse method on the flag set is called, the values will be read, parsed to the appropriate types, and assigned to the corresponding struct fields.
-func RegisterFlags(fs *flag.FlagSet, s interface{}, continueOnError bool) error {
+func RegisterFlags(fs *flag.FlagSet, s interface{}, continueOnError bool, opts ...Option) error {
 	v, err := validateStruct(s)
 	if err != nil {
 		return err
 	}
 
+	o := newOptions(opts...)
+
 	return iterateOnFields("", v, continueOnError, func(f fieldInfo) error {
-		if fs.Lookup(f.flag) != nil {
-			if continueOnError {
-				return nil
+		f = o.field(f)
+
+		names := []string{f.flag, f.short}
+		if f.negatable {
+			names = append(names, f.negation())
+		}
+
+		for _, name := range names {
+			if name != "" && fs.Lookup(name) != nil {
+				if continueOnError {
+					return nil
+				}
+				return fmt.Errorf("flag already registered: %s", name)
 			}
-			return fmt.Errorf("flag already registered: %s", f.flag)
+		}
+
+		o.report.add(f)
+
+		var def interface{} = f.value.Interface()
+		if set.IsCustomType(f.value.Type()) || isNetType(f.value.Type()) {
+			def = formatValue(f.value)
+		}
+
+		if f.unit != "" && f.value.Kind() != reflect.Slice {
+			def = set.FormatUnit(f.value, f.unit)
+		}
+
+		switch d := f.value.Interface().(type) {
+		case time.Duration:
+			def = set.FormatDuration(d)
+		case *time.Duration:
+			if d != nil {
+				def = set.FormatDuration(*d)
+			}
+		case []time.Duration:
+			formatted := []string{}
+			for _, e := range d {
+				formatted = append(formatted, set.FormatDuration(e))
+			}
+			def = "[" + strings.Join(formatted, " ") + "]"
+		}
+
+		if f.base != 0 && f.value.Kind() != reflect.Slice {
+			def = set.FormatBase(f.value, f.base)
+		}
+
+		if m, ok := f.value.Interface().(os.FileMode); ok && f.base == 0 {
+			// The symbolic notation of file modes cannot be parsed
+			def = fmt.Sprintf("%#o", uint32(m))
+		}
+
+		if name, ok := formatEnum(f); ok {
+			def = name
+		}
+
+		// The default values are already validated by iterateOnFields, so the error can be ignored
+		if ok, _ := f.setDefault(); ok {
+			def = f.def
+			o.report.set(f, OriginDefault, f.def, false)
 		}
 
 		// Create usage string
@@ -254,37 +972,191 @@ func RegisterFlags(fs *flag.FlagSet, s interface{}, continueOnError bool) error
 			usage = f.help + "\n"
 		}
 
-		switch f.value.Kind() {
+		kind := f.value.Kind()
+		if isSingleValue(f.value.Type()) {
+			// Custom types and net.HardwareAddr are set from a single value regardless of their kinds
+			kind = reflect.Invalid
+		}
+
+		switch kind {
 		case reflect.Slice:
 			usage += fmt.Sprintf("%-15s []%s\n%-15s %v\n%-15s %s",
 				"data type:", reflect.TypeOf(f.value.Interface()).Elem(),
-				"default value:", f.value.Interface(),
+				"default value:", def,
 				"separator:", f.sep,
 			)
+			if f.mode == modeAppend {
+				usage += fmt.Sprintf("\n%-15s %s", "repeatable:", "yes (values are appended)")
+			}
+		case reflect.Map:
+			usage += fmt.Sprintf("%-15s %s\n%-15s %v\n%-15s %s\n%-15s %s\n%-15s %s",
+				"data type:", f.value.Type(),
+				"default value:", def,
+				"separator:", f.sep,
+				"kv separator:", f.kvSep,
+				"repeatable:", "yes (entries are merged)",
+			)
 		case reflect.Struct:
 			usage += fmt.Sprintf("%-15s %s\n%-15s %+v",
 				"data type:", f.value.Type(),
-				"default value:", f.value.Interface(),
+				"default value:", def,
 			)
 		default:
 			usage += fmt.Sprintf("%-15s %s\n%-15s %v",
 				"data type:", f.value.Type(),
-				"default value:", f.value.Interface(),
+				"default value:", def,
 			)
 		}
 
+		if f.mode == modeCount {
+			usage += fmt.Sprintf("\n%-15s %s", "repeatable:", "yes (occurrences are counted)")
+		}
+
+		if f.unit != "" {
+			usage += fmt.Sprintf("\n%-15s %s", "unit:", f.unit)
+		}
+
+		if f.base != 0 {
+			usage += fmt.Sprintf("\n%-15s %d", "base:", f.base)
+		}
+
+		if len(f.enum) > 0 {
+			usage += fmt.Sprintf("\n%-15s %s", "choices:", set.ChoiceNames(f.enum))
+		}
+
+		if f.isTime() {
+			layout := f.layout
+			if layout == "" {
+				layout = time.RFC3339
+			}
+			usage += fmt.Sprintf("\n%-15s %s", "layout:", layout)
+		}
+
+		if f.negatable {
+			usage += fmt.Sprintf("\n%-15s -%s", "negation:", f.negation())
+		}
+
+		if f.env != "" {
+			usage += fmt.Sprintf("\n%-15s %s", "environment:", f.env)
+		}
+
+		if f.required {
+			usage += fmt.Sprintf("\n%-15s %s", "required:", "yes")
+		}
+
+		if len(f.rules) > 0 {
+			constraints := []string{}
+			for _, r := range f.rules {
+				constraints = append(constraints, r.String())
+			}
+			usage += fmt.Sprintf("\n%-15s %s", "constraints:", strings.Join(constraints, " "))
+		}
+
+		// The value from the environment variable becomes the default value of the flag
+		if ok, err := f.setEnv(); err != nil && !continueOnError {
+			return err
+		} else if ok {
+			o.report.set(f, OriginEnv, os.Getenv(f.env), false)
+		}
+
 		// Register the flag
-		switch f.value.Kind() {
-		case reflect.Bool:
-			// f.value.CanAddr() expected to be true
-			// f.value.Addr().Interface().(*bool) expected to be ok
-			ptr := f.value.Addr().Interface().(*bool)
-			fs.BoolVar(ptr, f.flag, f.value.Bool(), usage)
+		switch {
+		case f.negatable:
+			form := new(string)
+			pos := &negatableValue{
+				continueOnError: continueOnError,
+				field:           f,
+				name:            f.flag,
+				negated:         false,
+				form:            form,
+				report:          o.report,
+			}
+			neg := &negatableValue{
+				continueOnError: continueOnError,
+				field:           f,
+				name:            f.negation(),
+				negated:         true,
+				form:            form,
+				report:          o.report,
+			}
+
+			fs.Var(pos, f.flag, usage)
+			if f.short != "" {
+				fs.Var(pos, f.short, "shorthand for -"+f.flag)
+			}
+			fs.Var(neg, f.negation(), "negation of -"+f.flag)
+		case f.value.Type() == reflect.TypeOf(false):
+			bv := &boolValue{
+				field:  f,
+				report: o.report,
+			}
+			fs.Var(bv, f.flag, usage)
+			if f.short != "" {
+				fs.Var(bv, f.short, "shorthand for -"+f.flag)
+			}
 		default:
-			fv := &flagValue{continueOnError, f.value, f.sep}
+			fv := &flagValue{
+				continueOnError: continueOnError,
+				field:           f,
+				report:          o.report,
+			}
 			fs.Var(fv, f.flag, usage)
+			if f.short != "" {
+				fs.Var(fv, f.short, "shorthand for -"+f.flag)
+			}
 		}
 
 		return nil
 	})
 }
+
+// CheckRequired accepts a parsed flag set and the pointer to the struct type passed to RegisterFlags.
+// It returns an error listing all required flags that are neither set on the command-line nor have a non-zero value.
+// Since the flag package does not know about required flags, it should be called after the Parse method on the flag set.
+func CheckRequired(fs *flag.FlagSet, s interface{}, opts ...Option) error {
+	v, err := validateStruct(s)
+	if err != nil {
+		return err
+	}
+
+	visited := map[string]bool{}
+	fs.Visit(func(fl *flag.Flag) {
+		visited[fl.Name] = true
+	})
+
+	o := newOptions(opts...)
+	fields := []fieldInfo{}
+	seen := map[string]bool{}
+
+	// The schema errors are already reported by RegisterFlags
+	_ = iterateOnFields("", v, true, func(f fieldInfo) error {
+		f = o.field(f)
+		fields = append(fields, f)
+		seen[f.flag] = visited[f.flag] || visited[f.short] || (f.negatable && visited[f.negation()])
+
+		// The values from the environment variables are already assigned by RegisterFlags
+		if _, ok := os.LookupEnv(f.env); f.env != "" && ok {
+			seen[f.flag] = true
+		}
+
+		return nil
+	})
+
+	return checkRequired(fields, seen)
+}
+
+// checkRequired returns an error listing all required fields that are neither seen on the command-line nor have a non-zero value.
+func checkRequired(fields []fieldInfo, seen map[string]bool) error {
+	missing := []string{}
+	for _, f := range fields {
+		if f.required && !seen[f.flag] && f.value.IsZero() {
+			missing = append(missing, f.flag)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -3,14 +3,20 @@ package flagit
 import (
 	"errors"
 	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
 	"net/url"
 	"os"
 	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/moorara/flagit/ptr"
+	"github.com/moorara/flagit/set"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -106,6 +112,102 @@ func getFields(vStruct reflect.Value, handle func(f fieldInfo)) {
 	}
 }
 
+func TestFieldInfoIsBool(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected bool
+	}{
+		{"String", new(string), false},
+		{"Bool", new(bool), true},
+		{"BoolPointer", new(*bool), true},
+		{"BoolSlice", new([]bool), false},
+		{"Int", new(int), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := fieldInfo{
+				value: reflect.ValueOf(tc.value).Elem(),
+			}
+
+			assert.Equal(t, tc.expected, f.isBool())
+		})
+	}
+}
+
+func TestOptionsField(t *testing.T) {
+	tests := []struct {
+		name              string
+		opts              []Option
+		value             interface{}
+		hasNegatable      bool
+		env               string
+		expectedNegatable bool
+		expectedEnv       string
+	}{
+		{"Default_Bool", nil, new(bool), false, "", false, ""},
+		{"Negatable_Bool", []Option{Negatable()}, new(bool), false, "", true, ""},
+		{"Negatable_BoolPointer", []Option{Negatable()}, new(*bool), false, "", true, ""},
+		{"Negatable_String", []Option{Negatable()}, new(string), false, "", false, ""},
+		{"Negatable_TagFalse", []Option{Negatable()}, new(bool), true, "", false, ""},
+		{"EnvPrefix", []Option{EnvPrefix("APP")}, new(string), false, "", false, "APP_LOG_LEVEL"},
+		{"EnvPrefix_Empty", []Option{EnvPrefix("")}, new(string), false, "", false, "LOG_LEVEL"},
+		{"EnvPrefix_EnvTag", []Option{EnvPrefix("APP")}, new(string), false, "LEVEL", false, "LEVEL"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newOptions(tc.opts...)
+			f := o.field(fieldInfo{
+				value:        reflect.ValueOf(tc.value).Elem(),
+				flag:         "log.level",
+				hasNegatable: tc.hasNegatable,
+				env:          tc.env,
+			})
+
+			assert.Equal(t, tc.expectedNegatable, f.negatable)
+			assert.Equal(t, tc.expectedEnv, f.env)
+		})
+	}
+}
+
+func TestIncrement(t *testing.T) {
+	tests := []struct {
+		name            string
+		value           interface{}
+		repeated        bool
+		expectedUpdated bool
+		expectedError   string
+		expected        interface{}
+	}{
+		{"Int_First", ptr.Int(5), false, true, "", ptr.Int(1)},
+		{"Int_Repeated", ptr.Int(5), true, true, "", ptr.Int(6)},
+		{"Int8_Overflow", ptr.Int8(127), true, false, "value out of range: 128", ptr.Int8(127)},
+		{"Uint_First", ptr.Uint(5), false, true, "", ptr.Uint(1)},
+		{"Uint16_Repeated", ptr.Uint16(5), true, true, "", ptr.Uint16(6)},
+		{"Uint8_Overflow", ptr.Uint8(255), true, false, "value out of range: 256", ptr.Uint8(255)},
+		{"Uint64_Overflow", ptr.Uint64(18446744073709551615), true, false, "value out of range: 0", ptr.Uint64(18446744073709551615)},
+		{"String", ptr.String("foo"), true, false, "unsupported kind: string", ptr.String("foo")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.value).Elem()
+			updated, err := increment(v, tc.repeated)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expected, tc.value)
+		})
+	}
+}
+
 func TestFlagValue(t *testing.T) {
 	d := time.Second
 
@@ -119,8 +221,10 @@ func TestFlagValue(t *testing.T) {
 			name: "OK",
 			v: flagValue{
 				continueOnError: false,
-				value:           reflect.ValueOf(&d).Elem(),
-				sep:             ",",
+				field: fieldInfo{
+					value: reflect.ValueOf(&d).Elem(),
+					sep:   ",",
+				},
 			},
 			setVal:           "1m",
 			expectedSetError: "",
@@ -129,8 +233,10 @@ func TestFlagValue(t *testing.T) {
 			name: "Error",
 			v: flagValue{
 				continueOnError: false,
-				value:           reflect.ValueOf(&d).Elem(),
-				sep:             ",",
+				field: fieldInfo{
+					value: reflect.ValueOf(&d).Elem(),
+					sep:   ",",
+				},
 			},
 			setVal:           "invalid",
 			expectedSetError: `time: invalid duration "invalid"`,
@@ -139,8 +245,10 @@ func TestFlagValue(t *testing.T) {
 			name: "ContinueOnError",
 			v: flagValue{
 				continueOnError: true,
-				value:           reflect.ValueOf(&d).Elem(),
-				sep:             ",",
+				field: fieldInfo{
+					value: reflect.ValueOf(&d).Elem(),
+					sep:   ",",
+				},
 			},
 			setVal:           "invalid",
 			expectedSetError: "",
@@ -161,6 +269,151 @@ func TestFlagValue(t *testing.T) {
 	}
 }
 
+func TestFlagValueAppend(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		vals     []string
+		expected []string
+	}{
+		{
+			name:     "Replace",
+			mode:     "",
+			vals:     []string{"a", "b,c"},
+			expected: []string{"b", "c"},
+		},
+		{
+			name:     "Append",
+			mode:     "append",
+			vals:     []string{"a", "b,c"},
+			expected: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// The first occurrence should replace the default value
+			s := []string{"default"}
+
+			v := &flagValue{
+				field: fieldInfo{
+					value: reflect.ValueOf(&s).Elem(),
+					sep:   ",",
+					mode:  tc.mode,
+				},
+			}
+
+			for _, val := range tc.vals {
+				assert.NoError(t, v.Set(val))
+			}
+
+			assert.Equal(t, tc.expected, s)
+		})
+	}
+}
+
+func TestNegatableValue(t *testing.T) {
+	tests := []struct {
+		name            string
+		continueOnError bool
+		sets            [][2]string
+		expectedError   string
+		expected        bool
+	}{
+		{
+			name:     "Positive",
+			sets:     [][2]string{{"pos", "true"}},
+			expected: true,
+		},
+		{
+			name:     "Negative",
+			sets:     [][2]string{{"neg", "true"}},
+			expected: false,
+		},
+		{
+			name:     "NegativeFalse",
+			sets:     [][2]string{{"neg", "false"}},
+			expected: true,
+		},
+		{
+			name:          "InvalidValue",
+			sets:          [][2]string{{"pos", "invalid"}},
+			expectedError: `strconv.ParseBool: parsing "invalid": invalid syntax`,
+		},
+		{
+			name:          "Conflict",
+			sets:          [][2]string{{"neg", "true"}, {"pos", "true"}},
+			expectedError: "conflicting flags: no-color and color",
+		},
+		{
+			name:            "ContinueOnError",
+			continueOnError: true,
+			sets:            [][2]string{{"pos", "invalid"}, {"neg", "true"}, {"pos", "true"}},
+			expected:        false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := true
+			f := fieldInfo{
+				value:     reflect.ValueOf(&b).Elem(),
+				flag:      "color",
+				negatable: true,
+			}
+
+			form := new(string)
+			values := map[string]*negatableValue{
+				"pos": {continueOnError: tc.continueOnError, field: f, name: "color", negated: false, form: form},
+				"neg": {continueOnError: tc.continueOnError, field: f, name: "no-color", negated: true, form: form},
+			}
+
+			var err error
+			for _, set := range tc.sets {
+				v := values[set[0]]
+				assert.Empty(t, v.String())
+				assert.True(t, v.IsBoolFlag())
+
+				if err = v.Set(set[1]); err != nil {
+					break
+				}
+			}
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, b)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestFlagValueCount(t *testing.T) {
+	verbosity := 2
+	v := &flagValue{
+		field: fieldInfo{
+			value: reflect.ValueOf(&verbosity).Elem(),
+			mode:  "count",
+		},
+	}
+
+	assert.True(t, v.IsBoolFlag())
+
+	// The first occurrence should replace the default value
+	assert.NoError(t, v.Set("true"))
+	assert.Equal(t, 1, verbosity)
+
+	assert.NoError(t, v.Set("true"))
+	assert.NoError(t, v.Set("true"))
+	assert.Equal(t, 3, verbosity)
+
+	assert.NoError(t, v.Set("5"))
+	assert.Equal(t, 5, verbosity)
+
+	assert.False(t, (&flagValue{field: fieldInfo{mode: "append"}}).IsBoolFlag())
+}
+
 func TestValidateStruct(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -220,6 +473,21 @@ func TestIsStructSupported(t *testing.T) {
 			s:        regexp.Regexp{},
 			expected: true,
 		},
+		{
+			name:     "Time",
+			s:        time.Time{},
+			expected: true,
+		},
+		{
+			name:     "IPNet",
+			s:        net.IPNet{},
+			expected: true,
+		},
+		{
+			name:     "TCPAddr",
+			s:        net.TCPAddr{},
+			expected: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -254,6 +522,34 @@ func TestIsNestedStruct(t *testing.T) {
 	assert.True(t, isNestedStruct(vGroup.Type()))
 }
 
+func TestIsModeSupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		field    interface{}
+		expected bool
+	}{
+		{"Invalid", "invalid", []string{}, false},
+		{"Append_Slice", "append", []string{}, true},
+		{"Append_String", "append", "", false},
+		{"Append_IP", "append", net.IP{}, false},
+		{"Append_HardwareAddr", "append", net.HardwareAddr{}, false},
+		{"Count_Int", "count", int(0), true},
+		{"Count_Int8", "count", int8(0), true},
+		{"Count_Uint64", "count", uint64(0), true},
+		{"Count_IntPointer", "count", ptr.Int(0), false},
+		{"Count_String", "count", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			typ := reflect.TypeOf(tc.field)
+
+			assert.Equal(t, tc.expected, isModeSupported(tc.mode, typ))
+		})
+	}
+}
+
 func TestIsTypeSupported(t *testing.T) {
 	u, _ := url.Parse("service-1")
 	r := regexp.MustCompilePOSIX("[:digit:]")
@@ -314,6 +610,23 @@ func TestIsTypeSupported(t *testing.T) {
 		{"URLSlice", []url.URL{*u}, true},
 		{"RegexpSlice", []regexp.Regexp{*r}, true},
 		{"DurationSlice", []time.Duration{time.Second}, true},
+		{"StringMap", map[string]string{"env": "prod"}, true},
+		{"IntMap", map[string]int{"replicas": 3}, true},
+		{"UintKeyMap", map[uint8]bool{1: true}, true},
+		{"DurationMap", map[string]time.Duration{"read": time.Second}, true},
+		{"URLMap", map[string]url.URL{"api": *u}, true},
+		{"PointerMap", map[string]*string{"env": ptr.String("prod")}, false},
+		{"SliceMap", map[string][]string{"env": {"prod"}}, false},
+		{"NestedMap", map[string]map[string]string{}, false},
+		{"UnsupportedMap", map[string]interface{}{}, false},
+		{"TextUnmarshaler", logLevel(0), true},
+		{"TextUnmarshalerPointer", new(logLevel), true},
+		{"TextUnmarshalerSlice", []logLevel{}, true},
+		{"TextUnmarshalerMap", map[string]logLevel{}, true},
+		{"FlagValue", hostPort{}, true},
+		{"FlagValuePointer", &hostPort{}, true},
+		{"FlagValueSlice", []*hostPort{}, true},
+		{"Time", time.Time{}, true},
 	}
 
 	for _, tc := range tests {
@@ -325,58 +638,49 @@ func TestIsTypeSupported(t *testing.T) {
 	}
 }
 
-func TestGetFlagValue(t *testing.T) {
-	tests := []struct {
-		args              []string
-		flag              string
-		expectedFlagValue string
-	}{
-		{[]string{"app=invalid"}, "invalid", ""},
+func TestIterateOnFields(t *testing.T) {
+	invalid := struct {
+		LogLevel string `flag:"log level"`
+	}{}
 
-		{[]string{"app", "-enabled"}, "enabled", "true"},
-		{[]string{"app", "--enabled"}, "enabled", "true"},
-		{[]string{"app", "-enabled=false"}, "enabled", "false"},
-		{[]string{"app", "--enabled=false"}, "enabled", "false"},
-		{[]string{"app", "-enabled", "false"}, "enabled", "false"},
-		{[]string{"app", "--enabled", "false"}, "enabled", "false"},
+	invalidShort := struct {
+		LogLevel string `flag:"log-level" short:"ll"`
+	}{}
 
-		{[]string{"app", "-number=-10"}, "number", "-10"},
-		{[]string{"app", "--number=-10"}, "number", "-10"},
-		{[]string{"app", "-number", "-10"}, "number", "-10"},
-		{[]string{"app", "--number", "-10"}, "number", "-10"},
+	invalidMode := struct {
+		LogLevel string `flag:"log-level" mode:"append"`
+	}{}
 
-		{[]string{"app", "-text=content"}, "text", "content"},
-		{[]string{"app", "--text=content"}, "text", "content"},
-		{[]string{"app", "-text", "content"}, "text", "content"},
-		{[]string{"app", "--text", "content"}, "text", "content"},
+	invalidNegatable := struct {
+		LogLevel string `flag:"log-level" negatable:"true"`
+	}{}
 
-		{[]string{"app", "-enabled", "-text=content"}, "enabled", "true"},
-		{[]string{"app", "--enabled", "--text=content"}, "enabled", "true"},
-		{[]string{"app", "-enabled", "-text", "content"}, "enabled", "true"},
-		{[]string{"app", "--enabled", "--text", "content"}, "enabled", "true"},
+	invalidRequired := struct {
+		LogLevel string `flag:"log-level" required:"yes"`
+	}{}
 
-		{[]string{"app", "-name-list=alice,bob"}, "name-list", "alice,bob"},
-		{[]string{"app", "--name-list=alice,bob"}, "name-list", "alice,bob"},
-		{[]string{"app", "-name-list", "alice,bob"}, "name-list", "alice,bob"},
-		{[]string{"app", "--name-list", "alice,bob"}, "name-list", "alice,bob"},
-	}
+	invalidRule := struct {
+		LogLevel string `flag:"log-level" min:"1"`
+	}{}
 
-	origArgs := os.Args
-	defer func() {
-		os.Args = origArgs
-	}()
+	invalidDefault := struct {
+		Timeout time.Duration `flag:"timeout" default:"30"`
+	}{}
 
-	for _, tc := range tests {
-		os.Args = tc.args
-		flagValue := getFlagValue(tc.flag)
+	invalidTZ := struct {
+		Since time.Time `flag:"since" tz:"Mars/Olympus"`
+	}{}
 
-		assert.Equal(t, tc.expectedFlagValue, flagValue)
-	}
-}
+	invalidUnit := struct {
+		Timeout time.Duration `flag:"timeout" unit:"bytes"`
+	}{}
 
-func TestIterateOnFields(t *testing.T) {
-	invalid := struct {
-		LogLevel string `flag:"log level"`
+	invalidBase := struct {
+		Mask uint32 `flag:"mask" base:"64"`
+	}{}
+
+	invalidEnum := struct {
+		Level int `flag:"level" enum:"debug=0,info=one"`
 	}{}
 
 	tests := []struct {
@@ -407,93 +711,273 @@ func TestIterateOnFields(t *testing.T) {
 			expectedListSeps:   []string{},
 		},
 		{
-			name:            "OK",
-			s:               &Flags{},
-			continueOnError: false,
-			expectedError:   nil,
-			expectedFieldNames: []string{
-				"String",
-				"Bool",
-				"Float32", "Float64",
-				"Int", "Int8", "Int16", "Int32", "Int64",
-				"Uint", "Uint8", "Uint16", "Uint32", "Uint64",
-				"URL", "Regexp", "Duration",
-				"StringPointer",
-				"BoolPointer",
-				"Float32Pointer", "Float64Pointer",
-				"IntPointer", "Int8Pointer", "Int16Pointer", "Int32Pointer", "Int64Pointer",
-				"UintPointer", "Uint8Pointer", "Uint16Pointer", "Uint32Pointer", "Uint64Pointer",
-				"URLPointer", "RegexpPointer", "DurationPointer",
-				"StringSlice",
-				"BoolSlice",
-				"Float32Slice", "Float64Slice",
-				"IntSlice", "Int8Slice", "Int16Slice", "Int32Slice", "Int64Slice",
-				"UintSlice", "Uint8Slice", "Uint16Slice", "Uint32Slice", "Uint64Slice",
-				"URLSlice", "RegexpSlice", "DurationSlice",
-			},
-			expectedFlagNames: []string{
-				"string",
-				"bool",
-				"float32", "float64",
-				"int", "int8", "int16", "int32", "int64",
-				"uint", "uint8", "uint16", "uint32", "uint64",
-				"url", "regexp", "duration",
-				"string-pointer",
-				"bool-pointer",
-				"float32-pointer", "float64-pointer",
-				"int-pointer", "int8-pointer", "int16-pointer", "int32-pointer", "int64-pointer",
-				"uint-pointer", "uint8-pointer", "uint16-pointer", "uint32-pointer", "uint64-pointer",
-				"url-pointer", "regexp-pointer", "duration-pointer",
-				"string-slice",
-				"bool-slice",
-				"float32-slice", "float64-slice",
-				"int-slice", "int8-slice", "int16-slice", "int32-slice", "int64-slice",
-				"uint-slice", "uint8-slice", "uint16-slice", "uint32-slice", "uint64-slice",
-				"url-slice", "regexp-slice", "duration-slice",
-			},
-			expectedListSeps: []string{
-				",",
-				",",
-				",", ",",
-				",", ",", ",", ",", ",",
-				",", ",", ",", ",", ",",
-				",", ",", ",",
-				",",
-				",",
-				",", ",",
-				",", ",", ",", ",", ",",
-				",", ",", ",", ",", ",",
-				",", ",", ",",
-				",",
-				",",
-				",", ",",
-				",", ",", ",", ",", ",",
-				",", ",", ",", ",", ",",
-				",", ",", ",",
-			},
+			name:               "InvalidShort_StopOnError",
+			s:                  &invalidShort,
+			continueOnError:    false,
+			expectedError:      errors.New("invalid short flag name: ll"),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
 		},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			fieldNames := []string{}
-			flagNames := []string{}
-			listSeps := []string{}
-
-			vStruct, err := validateStruct(tc.s)
-			assert.NoError(t, err)
-
-			err = iterateOnFields("", vStruct, tc.continueOnError, func(f fieldInfo) error {
-				fieldNames = append(fieldNames, f.name)
-				flagNames = append(flagNames, f.flag)
-				listSeps = append(listSeps, f.sep)
-				return nil
-			})
-
-			assert.Equal(t, tc.expectedError, err)
-			assert.Equal(t, tc.expectedFieldNames, fieldNames)
-			assert.Equal(t, tc.expectedFlagNames, flagNames)
-			assert.Equal(t, tc.expectedListSeps, listSeps)
+		{
+			name:               "InvalidShort_ContinueOnError",
+			s:                  &invalidShort,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidMode_StopOnError",
+			s:                  &invalidMode,
+			continueOnError:    false,
+			expectedError:      errors.New("invalid mode for flag log-level: append"),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidMode_ContinueOnError",
+			s:                  &invalidMode,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidNegatable_StopOnError",
+			s:                  &invalidNegatable,
+			continueOnError:    false,
+			expectedError:      errors.New("invalid negatable for flag log-level: true"),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidNegatable_ContinueOnError",
+			s:                  &invalidNegatable,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidRequired_StopOnError",
+			s:                  &invalidRequired,
+			continueOnError:    false,
+			expectedError:      errors.New("invalid required for flag log-level: yes"),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidRequired_ContinueOnError",
+			s:                  &invalidRequired,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidRule_StopOnError",
+			s:                  &invalidRule,
+			continueOnError:    false,
+			expectedError:      errors.New("invalid validation rule for flag log-level: min=1"),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidRule_ContinueOnError",
+			s:                  &invalidRule,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidDefault_StopOnError",
+			s:                  &invalidDefault,
+			continueOnError:    false,
+			expectedError:      errors.New(`invalid default value for flag timeout: time: missing unit in duration "30"`),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidDefault_ContinueOnError",
+			s:                  &invalidDefault,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidTZ_StopOnError",
+			s:                  &invalidTZ,
+			continueOnError:    false,
+			expectedError:      errors.New("invalid tz for flag since: Mars/Olympus"),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidTZ_ContinueOnError",
+			s:                  &invalidTZ,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidUnit_StopOnError",
+			s:                  &invalidUnit,
+			continueOnError:    false,
+			expectedError:      errors.New("invalid unit for flag timeout: bytes"),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidUnit_ContinueOnError",
+			s:                  &invalidUnit,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidBase_StopOnError",
+			s:                  &invalidBase,
+			continueOnError:    false,
+			expectedError:      errors.New("invalid base for flag mask: 64"),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidBase_ContinueOnError",
+			s:                  &invalidBase,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidEnum_StopOnError",
+			s:                  &invalidEnum,
+			continueOnError:    false,
+			expectedError:      errors.New(`invalid enum for flag level: strconv.ParseInt: parsing "one": invalid syntax`),
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:               "InvalidEnum_ContinueOnError",
+			s:                  &invalidEnum,
+			continueOnError:    true,
+			expectedError:      nil,
+			expectedFieldNames: []string{},
+			expectedFlagNames:  []string{},
+			expectedListSeps:   []string{},
+		},
+		{
+			name:            "OK",
+			s:               &Flags{},
+			continueOnError: false,
+			expectedError:   nil,
+			expectedFieldNames: []string{
+				"String",
+				"Bool",
+				"Float32", "Float64",
+				"Int", "Int8", "Int16", "Int32", "Int64",
+				"Uint", "Uint8", "Uint16", "Uint32", "Uint64",
+				"URL", "Regexp", "Duration",
+				"StringPointer",
+				"BoolPointer",
+				"Float32Pointer", "Float64Pointer",
+				"IntPointer", "Int8Pointer", "Int16Pointer", "Int32Pointer", "Int64Pointer",
+				"UintPointer", "Uint8Pointer", "Uint16Pointer", "Uint32Pointer", "Uint64Pointer",
+				"URLPointer", "RegexpPointer", "DurationPointer",
+				"StringSlice",
+				"BoolSlice",
+				"Float32Slice", "Float64Slice",
+				"IntSlice", "Int8Slice", "Int16Slice", "Int32Slice", "Int64Slice",
+				"UintSlice", "Uint8Slice", "Uint16Slice", "Uint32Slice", "Uint64Slice",
+				"URLSlice", "RegexpSlice", "DurationSlice",
+			},
+			expectedFlagNames: []string{
+				"string",
+				"bool",
+				"float32", "float64",
+				"int", "int8", "int16", "int32", "int64",
+				"uint", "uint8", "uint16", "uint32", "uint64",
+				"url", "regexp", "duration",
+				"string-pointer",
+				"bool-pointer",
+				"float32-pointer", "float64-pointer",
+				"int-pointer", "int8-pointer", "int16-pointer", "int32-pointer", "int64-pointer",
+				"uint-pointer", "uint8-pointer", "uint16-pointer", "uint32-pointer", "uint64-pointer",
+				"url-pointer", "regexp-pointer", "duration-pointer",
+				"string-slice",
+				"bool-slice",
+				"float32-slice", "float64-slice",
+				"int-slice", "int8-slice", "int16-slice", "int32-slice", "int64-slice",
+				"uint-slice", "uint8-slice", "uint16-slice", "uint32-slice", "uint64-slice",
+				"url-slice", "regexp-slice", "duration-slice",
+			},
+			expectedListSeps: []string{
+				",",
+				",",
+				",", ",",
+				",", ",", ",", ",", ",",
+				",", ",", ",", ",", ",",
+				",", ",", ",",
+				",",
+				",",
+				",", ",",
+				",", ",", ",", ",", ",",
+				",", ",", ",", ",", ",",
+				",", ",", ",",
+				",",
+				",",
+				",", ",",
+				",", ",", ",", ",", ",",
+				",", ",", ",", ",", ",",
+				",", ",", ",",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fieldNames := []string{}
+			flagNames := []string{}
+			listSeps := []string{}
+
+			vStruct, err := validateStruct(tc.s)
+			assert.NoError(t, err)
+
+			err = iterateOnFields("", vStruct, tc.continueOnError, func(f fieldInfo) error {
+				fieldNames = append(fieldNames, f.name)
+				flagNames = append(flagNames, f.flag)
+				listSeps = append(listSeps, f.sep)
+				return nil
+			})
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedFieldNames, fieldNames)
+			assert.Equal(t, tc.expectedFlagNames, flagNames)
+			assert.Equal(t, tc.expectedListSeps, listSeps)
 		})
 	}
 }
@@ -669,6 +1153,25 @@ func TestPopulate(t *testing.T) {
 			`strconv.ParseInt: parsing "invalid": invalid syntax`,
 			&Flags{},
 		},
+		{
+			"UndefinedFlags",
+			[]string{
+				"app",
+				"-test.v=true",
+				"-test.run", "TestPopulate",
+				"--port=8080",
+				"-xyz",
+				"-string=foo",
+			},
+			&Flags{},
+			false,
+			"",
+			&Flags{
+				Values: Values{
+					String: "foo",
+				},
+			},
+		},
 		{
 			"ContinueOnError",
 			[]string{
@@ -751,40 +1254,180 @@ func TestPopulate(t *testing.T) {
 	}
 }
 
-func TestRegisterFlags(t *testing.T) {
-	fs := flag.NewFlagSet("app", flag.ContinueOnError)
-	fs.String("string", "", "")
-
-	url1, _ := url.Parse("service-1")
-	url2, _ := url.Parse("service-2")
-
-	re1 := regexp.MustCompilePOSIX("[:digit:]")
-	re2 := regexp.MustCompilePOSIX("[:alpha:]")
+func TestPopulateArgs(t *testing.T) {
+	type spec struct {
+		Verbose bool     `flag:"verbose" short:"v"`
+		Name    string   `flag:"name" short:"n"`
+		Tags    []string `flag:"tag" short:"t" mode:"append"`
+		Options struct {
+			Port uint16 `flag:"port"`
+		} `flag:"options-"`
+	}
 
-	flags := &Flags{
-		Values: Values{
-			String:   "foo",
-			Bool:     false,
-			Float32:  3.1415,
-			Float64:  3.14159265359,
-			Int:      -9223372036854775808,
-			Int8:     -128,
-			Int16:    -32768,
-			Int32:    -2147483648,
-			Int64:    -9223372036854775808,
-			Uint:     0,
-			Uint8:    0,
-			Uint16:   0,
-			Uint32:   0,
-			Uint64:   0,
-			URL:      *url1,
-			Regexp:   *re1,
-			Duration: time.Second,
-		},
-		Pointers: Pointers{
-			StringPointer:   ptr.String("foo"),
-			BoolPointer:     ptr.Bool(false),
-			Float32Pointer:  ptr.Float32(3.1415),
+	tests := []struct {
+		name            string
+		args            []string
+		s               interface{}
+		continueOnError bool
+		expectedError   string
+		expectedArgs    []string
+		expected        interface{}
+	}{
+		{
+			name:            "NonStruct",
+			args:            []string{},
+			s:               new(string),
+			continueOnError: false,
+			expectedError:   "non-struct type: you should pass a pointer to a struct type",
+		},
+		{
+			name:            "NoArgs",
+			args:            nil,
+			s:               &spec{},
+			continueOnError: false,
+			expectedError:   "",
+			expectedArgs:    []string{},
+			expected:        &spec{},
+		},
+		{
+			name:            "OK",
+			args:            []string{"-verbose", "--name", "alice", "--options-port=8080", "input.txt", "--", "--name=bob"},
+			s:               &spec{},
+			continueOnError: false,
+			expectedError:   "",
+			expectedArgs:    []string{"input.txt", "--name=bob"},
+			expected: &spec{
+				Verbose: true,
+				Name:    "alice",
+				Options: struct {
+					Port uint16 `flag:"port"`
+				}{
+					Port: 8080,
+				},
+			},
+		},
+		{
+			name:            "RepeatedFlags",
+			args:            []string{"--name", "alice", "--tag", "a", "--name", "bob", "--tag=b,c"},
+			s:               &spec{},
+			continueOnError: false,
+			expectedError:   "",
+			expectedArgs:    []string{},
+			expected: &spec{
+				Name: "bob",
+				Tags: []string{"a", "b", "c"},
+			},
+		},
+		{
+			name:            "ShortFlags",
+			args:            []string{"-vn", "alice", "-ta", "-t", "b", "input.txt"},
+			s:               &spec{},
+			continueOnError: false,
+			expectedError:   "",
+			expectedArgs:    []string{"input.txt"},
+			expected: &spec{
+				Verbose: true,
+				Name:    "alice",
+				Tags:    []string{"a", "b"},
+			},
+		},
+		{
+			name:            "RepeatedFlags_ReplaceDefault",
+			args:            []string{"--tag", "a", "--tag", "b"},
+			s:               &spec{Tags: []string{"default"}},
+			continueOnError: false,
+			expectedError:   "",
+			expectedArgs:    []string{},
+			expected: &spec{
+				Tags: []string{"a", "b"},
+			},
+		},
+		{
+			name:            "BoolFollowedByPositional",
+			args:            []string{"--verbose", "input.txt"},
+			s:               &spec{},
+			continueOnError: false,
+			expectedError:   "",
+			expectedArgs:    []string{"input.txt"},
+			expected: &spec{
+				Verbose: true,
+			},
+		},
+		{
+			name:            "StopOnError",
+			args:            []string{"--options-port=invalid"},
+			s:               &spec{},
+			continueOnError: false,
+			expectedError:   `strconv.ParseUint: parsing "invalid": invalid syntax`,
+		},
+		{
+			name:            "UndefinedFlag",
+			args:            []string{"--port=8080"},
+			s:               &spec{},
+			continueOnError: false,
+			expectedError:   "flag provided but not defined: --port",
+		},
+		{
+			name:            "ContinueOnError",
+			args:            []string{"--port=8080", "--options-port=invalid"},
+			s:               &spec{},
+			continueOnError: true,
+			expectedError:   "",
+			expectedArgs:    []string{},
+			expected:        &spec{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := PopulateArgs(tc.s, tc.args, tc.continueOnError)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedArgs, args)
+				assert.Equal(t, tc.expected, tc.s)
+			} else {
+				assert.Nil(t, args)
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.String("string", "", "")
+
+	url1, _ := url.Parse("service-1")
+	url2, _ := url.Parse("service-2")
+
+	re1 := regexp.MustCompilePOSIX("[:digit:]")
+	re2 := regexp.MustCompilePOSIX("[:alpha:]")
+
+	flags := &Flags{
+		Values: Values{
+			String:   "foo",
+			Bool:     false,
+			Float32:  3.1415,
+			Float64:  3.14159265359,
+			Int:      -9223372036854775808,
+			Int8:     -128,
+			Int16:    -32768,
+			Int32:    -2147483648,
+			Int64:    -9223372036854775808,
+			Uint:     0,
+			Uint8:    0,
+			Uint16:   0,
+			Uint32:   0,
+			Uint64:   0,
+			URL:      *url1,
+			Regexp:   *re1,
+			Duration: time.Second,
+		},
+		Pointers: Pointers{
+			StringPointer:   ptr.String("foo"),
+			BoolPointer:     ptr.Bool(false),
+			Float32Pointer:  ptr.Float32(3.1415),
 			Float64Pointer:  ptr.Float64(3.14159265359),
 			IntPointer:      ptr.Int(-9223372036854775808),
 			Int8Pointer:     ptr.Int8(-128),
@@ -1030,3 +1673,1943 @@ func TestRegisterFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisterFlagsShort(t *testing.T) {
+	type spec struct {
+		Verbose bool     `flag:"verbose,enable verbose logs" short:"v"`
+		Name    string   `flag:"name" short:"n"`
+		Tags    []string `flag:"tag" short:"t" mode:"append"`
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		s := &spec{}
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+
+		err := RegisterFlags(fs, s, false)
+		assert.NoError(t, err)
+		assert.Equal(t, "shorthand for -verbose", fs.Lookup("v").Usage)
+		assert.Equal(t, "shorthand for -name", fs.Lookup("n").Usage)
+
+		err = fs.Parse([]string{"-v", "-n", "alice", "-t", "a", "--tag", "b", "-t=c"})
+		assert.NoError(t, err)
+		assert.Equal(t, &spec{
+			Verbose: true,
+			Name:    "alice",
+			Tags:    []string{"a", "b", "c"},
+		}, s)
+	})
+
+	t.Run("ShortRegistered_StopOnError", func(t *testing.T) {
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.String("n", "", "")
+
+		err := RegisterFlags(fs, &spec{}, false)
+		assert.EqualError(t, err, "flag already registered: n")
+	})
+
+	t.Run("ShortRegistered_ContinueOnError", func(t *testing.T) {
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.String("n", "", "")
+
+		err := RegisterFlags(fs, &spec{}, true)
+		assert.NoError(t, err)
+		assert.Nil(t, fs.Lookup("name"))
+		assert.NotNil(t, fs.Lookup("verbose"))
+	})
+}
+
+func TestPopulateArgsNegatable(t *testing.T) {
+	type spec struct {
+		Color   bool  `flag:"color" negatable:"true"`
+		Verbose *bool `flag:"verbose" short:"v"`
+		Debug   bool  `flag:"debug"`
+		Quiet   bool  `flag:"quiet" negatable:"false"`
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		opts          []Option
+		expectedError string
+		expectedArgs  []string
+		expected      *spec
+	}{
+		{
+			name:         "Tag",
+			args:         []string{"--no-color", "input.txt"},
+			expectedArgs: []string{"input.txt"},
+			expected: &spec{
+				Color: false,
+				Debug: true,
+			},
+		},
+		{
+			name:          "Tag_NotNegatable",
+			args:          []string{"--no-debug"},
+			expectedError: "flag provided but not defined: --no-debug",
+		},
+		{
+			name:         "Option",
+			args:         []string{"--no-color", "--no-verbose", "--no-debug"},
+			opts:         []Option{Negatable()},
+			expectedArgs: []string{},
+			expected: &spec{
+				Color:   false,
+				Verbose: ptr.Bool(false),
+				Debug:   false,
+			},
+		},
+		{
+			name:          "Option_TagFalse",
+			args:          []string{"--no-quiet"},
+			opts:          []Option{Negatable()},
+			expectedError: "flag provided but not defined: --no-quiet",
+		},
+		{
+			name:          "Conflict",
+			args:          []string{"-v", "--no-verbose"},
+			opts:          []Option{Negatable()},
+			expectedError: "conflicting flags: verbose and no-verbose",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{
+				Color: true,
+				Debug: true,
+			}
+
+			args, err := PopulateArgs(s, tc.args, false, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedArgs, args)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsNegatable(t *testing.T) {
+	type spec struct {
+		Color   bool  `flag:"color,colorize the output" negatable:"true"`
+		Verbose *bool `flag:"verbose" short:"v"`
+		Debug   bool  `flag:"debug"`
+	}
+
+	tests := []struct {
+		name               string
+		args               []string
+		opts               []Option
+		expectedFlags      []string
+		expectedParseError string
+		expected           *spec
+	}{
+		{
+			name:          "Tag",
+			args:          []string{"-no-color"},
+			expectedFlags: []string{"color", "debug", "no-color", "v", "verbose"},
+			expected: &spec{
+				Color: false,
+				Debug: true,
+			},
+		},
+		{
+			name:          "Option",
+			args:          []string{"-no-color", "-no-verbose", "-no-debug"},
+			opts:          []Option{Negatable()},
+			expectedFlags: []string{"color", "debug", "no-color", "no-debug", "no-verbose", "v", "verbose"},
+			expected: &spec{
+				Color:   false,
+				Verbose: ptr.Bool(false),
+				Debug:   false,
+			},
+		},
+		{
+			name:               "Conflict",
+			args:               []string{"-color", "-no-color"},
+			expectedFlags:      []string{"color", "debug", "no-color", "v", "verbose"},
+			expectedParseError: "invalid boolean flag no-color: conflicting flags: color and no-color",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{
+				Color: true,
+				Debug: true,
+			}
+
+			fs := flag.NewFlagSet("app", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+
+			err := RegisterFlags(fs, s, false, tc.opts...)
+			assert.NoError(t, err)
+
+			flags := []string{}
+			fs.VisitAll(func(f *flag.Flag) {
+				flags = append(flags, f.Name)
+			})
+			assert.Equal(t, tc.expectedFlags, flags)
+			assert.Contains(t, fs.Lookup("color").Usage, "negation:       -no-color")
+			assert.Equal(t, "negation of -color", fs.Lookup("no-color").Usage)
+
+			err = fs.Parse(tc.args)
+
+			if tc.expectedParseError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedParseError)
+			}
+		})
+	}
+}
+
+func TestPopulateArgsCount(t *testing.T) {
+	type spec struct {
+		Verbosity uint8 `flag:"verbose" short:"v" mode:"count"`
+		Quiet     bool  `flag:"quiet" short:"q"`
+		Level     int   `flag:"level" short:"l" mode:"count"`
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expectedArgs  []string
+		expected      *spec
+	}{
+		{
+			name:         "NoFlag",
+			args:         []string{},
+			expectedArgs: []string{},
+			expected:     &spec{Level: 2},
+		},
+		{
+			name:         "Repeated",
+			args:         []string{"-v", "--verbose", "-verbose", "input.txt"},
+			expectedArgs: []string{"input.txt"},
+			expected:     &spec{Verbosity: 3, Level: 2},
+		},
+		{
+			name:         "Cluster",
+			args:         []string{"-vvqv", "-ll"},
+			expectedArgs: []string{},
+			expected:     &spec{Verbosity: 3, Quiet: true, Level: 2},
+		},
+		{
+			name:         "ExplicitValue",
+			args:         []string{"--verbose=5", "-v", "-l=7"},
+			expectedArgs: []string{},
+			expected:     &spec{Verbosity: 6, Level: 7},
+		},
+		{
+			name:          "InvalidValue",
+			args:          []string{"--verbose=invalid"},
+			expectedError: `strconv.ParseUint: parsing "invalid": invalid syntax`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{Level: 2}
+			args, err := PopulateArgs(s, tc.args, false)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedArgs, args)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestPopulateArgsMap(t *testing.T) {
+	type spec struct {
+		Labels   map[string]string        `flag:"labels"`
+		Limits   map[string]int           `flag:"limits" sep:";" kvsep:":"`
+		Timeouts map[string]time.Duration `flag:"timeouts" short:"t"`
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expected      *spec
+	}{
+		{
+			name: "NoFlag",
+			args: []string{},
+			expected: &spec{
+				Labels: map[string]string{"team": "core"},
+			},
+		},
+		{
+			name: "ReplaceDefault",
+			args: []string{"--labels", "env=prod,tier=web"},
+			expected: &spec{
+				Labels: map[string]string{"env": "prod", "tier": "web"},
+			},
+		},
+		{
+			name: "Repeated",
+			args: []string{"--labels=env=dev", "--labels", "env=prod,tier=web", "-limits", "cpu:2;memory:512", "-t", "read=1s", "-tconnect=5s"},
+			expected: &spec{
+				Labels:   map[string]string{"env": "prod", "tier": "web"},
+				Limits:   map[string]int{"cpu": 2, "memory": 512},
+				Timeouts: map[string]time.Duration{"read": time.Second, "connect": 5 * time.Second},
+			},
+		},
+		{
+			name:          "InvalidPair",
+			args:          []string{"--labels", "env"},
+			expectedError: `invalid key-value pair: "env"`,
+		},
+		{
+			name:          "InvalidValue",
+			args:          []string{"--limits", "cpu:many"},
+			expectedError: `strconv.ParseInt: parsing "many": invalid syntax`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{
+				Labels: map[string]string{"team": "core"},
+			}
+
+			_, err := PopulateArgs(s, tc.args, false)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsMap(t *testing.T) {
+	type spec struct {
+		Labels map[string]string `flag:"labels,labels for the resources"`
+		Limits map[string]int    `flag:"limits" sep:";" kvsep:":"`
+	}
+
+	s := &spec{
+		Labels: map[string]string{"team": "core"},
+	}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "labels for the resources\n"+
+		"data type:      map[string]string\n"+
+		"default value:  map[team:core]\n"+
+		"separator:      ,\n"+
+		"kv separator:   =\n"+
+		"repeatable:     yes (entries are merged)",
+		fs.Lookup("labels").Usage,
+	)
+
+	err = fs.Parse([]string{"-labels", "env=dev", "-labels=env=prod,tier=web", "-limits", "cpu:2;memory:512"})
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{
+		Labels: map[string]string{"env": "prod", "tier": "web"},
+		Limits: map[string]int{"cpu": 2, "memory": 512},
+	}, s)
+}
+
+func TestPopulateArgsEnv(t *testing.T) {
+	type spec struct {
+		Debug bool `flag:"debug" env:"DEBUG"`
+		Log   struct {
+			Level string `flag:"level"`
+		} `flag:"log."`
+		Port   int               `flag:"port" env:"PORT"`
+		Tags   []string          `flag:"tag" mode:"append"`
+		Labels map[string]string `flag:"labels"`
+	}
+
+	tests := []struct {
+		name          string
+		env           map[string]string
+		args          []string
+		opts          []Option
+		expectedError string
+		expected      *spec
+	}{
+		{
+			name: "EnvTag",
+			env: map[string]string{
+				"DEBUG": "true",
+				"PORT":  "8080",
+			},
+			args:     []string{},
+			expected: &spec{Debug: true, Port: 8080},
+		},
+		{
+			name: "CommandLineOverridesEnv",
+			env: map[string]string{
+				"DEBUG": "true",
+				"PORT":  "8080",
+			},
+			args:     []string{"--port", "9090", "--debug=false"},
+			expected: &spec{Port: 9090},
+		},
+		{
+			name: "EnvPrefix",
+			env: map[string]string{
+				"APP_LOG_LEVEL": "debug",
+				"APP_TAG":       "a,b",
+				"APP_LABELS":    "env=prod",
+				"PORT":          "8080",
+				"APP_PORT":      "9090",
+			},
+			args: []string{},
+			opts: []Option{EnvPrefix("APP")},
+			expected: &spec{
+				Log: struct {
+					Level string `flag:"level"`
+				}{Level: "debug"},
+				Port:   8080,
+				Tags:   []string{"a", "b"},
+				Labels: map[string]string{"env": "prod"},
+			},
+		},
+		{
+			name: "EnvPrefix_CommandLineReplacesEnv",
+			env: map[string]string{
+				"APP_TAG": "a,b",
+			},
+			args:     []string{"--tag", "c", "--tag", "d"},
+			opts:     []Option{EnvPrefix("APP")},
+			expected: &spec{Tags: []string{"c", "d"}},
+		},
+		{
+			name: "InvalidValue",
+			env: map[string]string{
+				"PORT": "invalid",
+			},
+			args:          []string{},
+			expectedError: `invalid value "invalid" for environment variable PORT: strconv.ParseInt: parsing "invalid": invalid syntax`,
+		},
+		{
+			name: "InvalidValue_CommandLine",
+			env: map[string]string{
+				"PORT": "invalid",
+			},
+			args:     []string{"--port", "9090"},
+			expected: &spec{Port: 9090},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, val := range tc.env {
+				os.Setenv(name, val)
+				defer os.Unsetenv(name)
+			}
+
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, false, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsEnv(t *testing.T) {
+	type spec struct {
+		Port     int    `flag:"port,the port number" env:"PORT"`
+		LogLevel string `flag:"log-level"`
+	}
+
+	os.Setenv("PORT", "8080")
+	defer os.Unsetenv("PORT")
+
+	os.Setenv("APP_LOG_LEVEL", "debug")
+	defer os.Unsetenv("APP_LOG_LEVEL")
+
+	t.Run("OK", func(t *testing.T) {
+		s := &spec{Port: 80, LogLevel: "info"}
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+
+		err := RegisterFlags(fs, s, false, EnvPrefix("APP"))
+		assert.NoError(t, err)
+		assert.Equal(t, &spec{Port: 8080, LogLevel: "debug"}, s)
+		assert.Equal(t, "the port number\n"+
+			"data type:      int\n"+
+			"default value:  80\n"+
+			"environment:    PORT",
+			fs.Lookup("port").Usage,
+		)
+		assert.Contains(t, fs.Lookup("log-level").Usage, "environment:    APP_LOG_LEVEL")
+
+		err = fs.Parse([]string{"-port", "9090"})
+		assert.NoError(t, err)
+		assert.Equal(t, &spec{Port: 9090, LogLevel: "debug"}, s)
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		os.Setenv("APP_PORT", "invalid")
+		defer os.Unsetenv("APP_PORT")
+
+		type spec struct {
+			Port int `flag:"port"`
+		}
+
+		s := &spec{}
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+
+		err := RegisterFlags(fs, s, false, EnvPrefix("APP"))
+		assert.EqualError(t, err, `invalid value "invalid" for environment variable APP_PORT: strconv.ParseInt: parsing "invalid": invalid syntax`)
+
+		err = RegisterFlags(fs, s, true, EnvPrefix("APP"))
+		assert.NoError(t, err)
+	})
+}
+
+func TestPopulateArgsDefault(t *testing.T) {
+	type spec struct {
+		Timeout time.Duration     `flag:"timeout" default:"30s"`
+		Port    int               `flag:"port" env:"PORT" default:"8080"`
+		Debug   *bool             `flag:"debug" default:"true"`
+		Tags    []string          `flag:"tag" mode:"append" default:"a,b"`
+		Labels  map[string]string `flag:"labels" default:"env=dev"`
+		Name    string            `flag:"name" default:"app"`
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		args     []string
+		s        *spec
+		expected *spec
+	}{
+		{
+			name: "Defaults",
+			args: []string{},
+			s:    &spec{},
+			expected: &spec{
+				Timeout: 30 * time.Second,
+				Port:    8080,
+				Debug:   ptr.Bool(true),
+				Tags:    []string{"a", "b"},
+				Labels:  map[string]string{"env": "dev"},
+				Name:    "app",
+			},
+		},
+		{
+			name: "ExplicitValues",
+			args: []string{},
+			s: &spec{
+				Timeout: time.Minute,
+				Name:    "server",
+			},
+			expected: &spec{
+				Timeout: time.Minute,
+				Port:    8080,
+				Debug:   ptr.Bool(true),
+				Tags:    []string{"a", "b"},
+				Labels:  map[string]string{"env": "dev"},
+				Name:    "server",
+			},
+		},
+		{
+			name: "CommandLineAndEnv",
+			env: map[string]string{
+				"PORT": "9090",
+			},
+			args: []string{"--timeout", "1m", "--debug=false", "--tag", "c", "--labels", "env=prod"},
+			s:    &spec{},
+			expected: &spec{
+				Timeout: time.Minute,
+				Port:    9090,
+				Debug:   ptr.Bool(false),
+				Tags:    []string{"c"},
+				Labels:  map[string]string{"env": "prod"},
+				Name:    "app",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, val := range tc.env {
+				os.Setenv(name, val)
+				defer os.Unsetenv(name)
+			}
+
+			_, err := PopulateArgs(tc.s, tc.args, false)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, tc.s)
+		})
+	}
+}
+
+func TestRegisterFlagsDefault(t *testing.T) {
+	type spec struct {
+		Timeout time.Duration `flag:"timeout,the request timeout" default:"1m30s"`
+		Tags    []string      `flag:"tag" default:"a,b"`
+		Name    string        `flag:"name" default:"app"`
+	}
+
+	s := &spec{Name: "server"}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{Timeout: 90 * time.Second, Tags: []string{"a", "b"}, Name: "server"}, s)
+	assert.Equal(t, "the request timeout\n"+
+		"data type:      time.Duration\n"+
+		"default value:  1m30s",
+		fs.Lookup("timeout").Usage,
+	)
+	assert.Contains(t, fs.Lookup("tag").Usage, "default value:  a,b")
+	assert.Contains(t, fs.Lookup("name").Usage, "default value:  server")
+
+	err = fs.Parse([]string{"-timeout", "10s"})
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{Timeout: 10 * time.Second, Tags: []string{"a", "b"}, Name: "server"}, s)
+
+	invalid := &struct {
+		Port int `flag:"port" default:"http"`
+	}{}
+
+	err = RegisterFlags(flag.NewFlagSet("app", flag.ContinueOnError), invalid, false)
+	assert.EqualError(t, err, `invalid default value for flag port: strconv.ParseInt: parsing "http": invalid syntax`)
+}
+
+func TestPopulateArgsRequired(t *testing.T) {
+	type spec struct {
+		Endpoints []string `flag:"endpoints" required:"true"`
+		Log       struct {
+			Level string `flag:"level" required:"true"`
+		} `flag:"log."`
+		Port    int  `flag:"port" env:"PORT" required:"true"`
+		Debug   bool `flag:"debug" required:"true"`
+		Timeout int  `flag:"timeout" default:"30" required:"true"`
+		Retries int  `flag:"retries" required:"false"`
+	}
+
+	tests := []struct {
+		name            string
+		env             map[string]string
+		args            []string
+		continueOnError bool
+		expectedError   string
+	}{
+		{
+			name:          "AllMissing",
+			args:          []string{},
+			expectedError: "missing required flags: endpoints, log.level, port, debug",
+		},
+		{
+			name:            "AllMissing_ContinueOnError",
+			args:            []string{},
+			continueOnError: true,
+		},
+		{
+			name: "SomeMissing",
+			env: map[string]string{
+				"PORT": "8080",
+			},
+			args:          []string{"--endpoints", "a,b"},
+			expectedError: "missing required flags: log.level, debug",
+		},
+		{
+			name:          "ZeroValueOnCommandLine",
+			args:          []string{"--endpoints=a", "--log.level", "info", "--port=0", "--debug=false"},
+			expectedError: "",
+		},
+		{
+			name: "ZeroValueInEnvironment",
+			env: map[string]string{
+				"PORT": "0",
+			},
+			args:          []string{"--endpoints=a", "--log.level", "info", "--debug=false"},
+			expectedError: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, val := range tc.env {
+				os.Setenv(name, val)
+				defer os.Unsetenv(name)
+			}
+
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, tc.continueOnError)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsRequired(t *testing.T) {
+	type spec struct {
+		Endpoints []string `flag:"endpoints,the service endpoints" required:"true"`
+		Log       struct {
+			Level string `flag:"level" required:"true"`
+		} `flag:"log."`
+		Debug bool `flag:"debug" short:"d" required:"true"`
+		Port  int  `flag:"port" env:"PORT" required:"true"`
+	}
+
+	tests := []struct {
+		name          string
+		s             interface{}
+		env           map[string]string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "NonPointer",
+			s:             spec{},
+			args:          []string{},
+			expectedError: "non-pointer type: you should pass a pointer to a struct type",
+		},
+		{
+			name:          "AllMissing",
+			s:             &spec{},
+			args:          []string{},
+			expectedError: "missing required flags: endpoints, log.level, debug, port",
+		},
+		{
+			name:          "SomeMissing",
+			s:             &spec{Port: 8080},
+			args:          []string{"-endpoints", "a,b"},
+			expectedError: "missing required flags: log.level, debug",
+		},
+		{
+			name: "OK",
+			s:    &spec{},
+			args: []string{"-endpoints", "a,b", "-log.level", "info", "-d=false", "-port", "0"},
+		},
+		{
+			name: "ZeroValueInEnvironment",
+			s:    &spec{},
+			env: map[string]string{
+				"PORT": "0",
+			},
+			args: []string{"-endpoints", "a,b", "-log.level", "info", "-d=false"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, val := range tc.env {
+				os.Setenv(name, val)
+				defer os.Unsetenv(name)
+			}
+
+			fs := flag.NewFlagSet("app", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+
+			if _, ok := tc.s.(*spec); ok {
+				err := RegisterFlags(fs, tc.s, false)
+				assert.NoError(t, err)
+				assert.Equal(t, "the service endpoints\n"+
+					"data type:      []string\n"+
+					"default value:  []\n"+
+					"separator:      ,\n"+
+					"required:       yes",
+					fs.Lookup("endpoints").Usage,
+				)
+			}
+
+			err := fs.Parse(tc.args)
+			assert.NoError(t, err)
+
+			err = CheckRequired(fs, tc.s)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestPopulateArgsRecord(t *testing.T) {
+	type spec struct {
+		Debug   bool          `flag:"debug" short:"d"`
+		Port    int           `flag:"port" env:"PORT"`
+		Timeout time.Duration `flag:"timeout" default:"30s"`
+		Tags    []string      `flag:"tag" mode:"append"`
+		Name    string        `flag:"name"`
+		Region  string        `flag:"region"`
+		Level   int           `flag:"level" enum:"debug=0,info=1" default:"info"`
+	}
+
+	os.Setenv("PORT", "8080")
+	defer os.Unsetenv("PORT")
+
+	s := &spec{Name: "app"}
+	r := &Report{}
+
+	_, err := PopulateArgs(s, []string{"-d", "--tag", "a", "--tag", "b,c"}, false, Record(r))
+	assert.NoError(t, err)
+
+	expected := map[string]struct {
+		origin Origin
+		raw    []string
+	}{
+		"debug":   {OriginFlag, []string{"true"}},
+		"port":    {OriginEnv, []string{"8080"}},
+		"timeout": {OriginDefault, []string{"30s"}},
+		"tag":     {OriginFlag, []string{"a", "b,c"}},
+		"name":    {OriginProgram, []string{}},
+		"region":  {OriginNone, []string{}},
+		"level":   {OriginDefault, []string{"info"}},
+	}
+
+	assert.Len(t, r.Entries(), len(expected))
+
+	for flag, exp := range expected {
+		e, ok := r.Lookup(flag)
+		assert.True(t, ok)
+		assert.Equal(t, exp.origin, e.Origin, flag)
+		assert.Equal(t, exp.raw, e.Raw, flag)
+	}
+
+	e, _ := r.Lookup("tag")
+	assert.Equal(t, []string{"a", "b", "c"}, e.Value())
+	assert.Nil(t, e.Choices)
+
+	e, _ = r.Lookup("level")
+	assert.Equal(t, 1, e.Value())
+	assert.Equal(t, []string{"debug", "info"}, e.Choices)
+}
+
+func TestRegisterFlagsRecord(t *testing.T) {
+	type spec struct {
+		Debug   bool          `flag:"debug" short:"d"`
+		Verbose bool          `flag:"verbose" negatable:"true"`
+		Port    int           `flag:"port" env:"PORT"`
+		Timeout time.Duration `flag:"timeout" default:"30s"`
+		Name    string        `flag:"name"`
+	}
+
+	os.Setenv("PORT", "8080")
+	defer os.Unsetenv("PORT")
+
+	s := &spec{Name: "app"}
+	r := &Report{}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false, Record(r))
+	assert.NoError(t, err)
+
+	err = fs.Parse([]string{"-d", "-no-verbose", "-timeout", "1m"})
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{Debug: true, Port: 8080, Timeout: time.Minute, Name: "app"}, s)
+
+	expected := map[string]struct {
+		origin Origin
+		raw    []string
+	}{
+		"debug":   {OriginFlag, []string{"true"}},
+		"verbose": {OriginFlag, []string{"false"}},
+		"port":    {OriginEnv, []string{"8080"}},
+		"timeout": {OriginFlag, []string{"1m"}},
+		"name":    {OriginProgram, []string{}},
+	}
+
+	for flag, exp := range expected {
+		e, ok := r.Lookup(flag)
+		assert.True(t, ok)
+		assert.Equal(t, exp.origin, e.Origin, flag)
+		assert.Equal(t, exp.raw, e.Raw, flag)
+	}
+
+	err = fs.Parse([]string{"-debug=invalid"})
+	assert.EqualError(t, err, `invalid boolean value "invalid" for -debug: parse error`)
+}
+
+func TestPopulateArgsValidation(t *testing.T) {
+	type spec struct {
+		Port      int      `flag:"port" min:"1" max:"65535" default:"8080"`
+		Endpoints []string `flag:"endpoints" nonempty:"true" pattern:"^https?://"`
+		Log       struct {
+			Level string `flag:"level" oneof:"debug,info,warn,error" default:"info"`
+		} `flag:"log."`
+	}
+
+	tests := []struct {
+		name            string
+		args            []string
+		continueOnError bool
+		expectedError   error
+	}{
+		{
+			name:          "OK",
+			args:          []string{"--endpoints", "http://a,https://b"},
+			expectedError: nil,
+		},
+		{
+			name: "NonEmpty",
+			args: []string{},
+			expectedError: &ValidationError{
+				Flag:  "endpoints",
+				Rule:  "nonempty",
+				Param: "true",
+				Value: []string(nil),
+			},
+		},
+		{
+			name: "Max",
+			args: []string{"--endpoints", "http://a", "--port", "70000"},
+			expectedError: &ValidationError{
+				Flag:  "port",
+				Rule:  "max",
+				Param: "65535",
+				Value: 70000,
+			},
+		},
+		{
+			name: "Pattern",
+			args: []string{"--endpoints", "http://a,ftp://b"},
+			expectedError: &ValidationError{
+				Flag:  "endpoints",
+				Rule:  "pattern",
+				Param: "^https?://",
+				Value: []string{"http://a", "ftp://b"},
+			},
+		},
+		{
+			name: "OneOf",
+			args: []string{"--endpoints", "http://a", "--log.level", "trace"},
+			expectedError: &ValidationError{
+				Flag:  "log.level",
+				Rule:  "oneof",
+				Param: "debug,info,warn,error",
+				Value: "trace",
+			},
+		},
+		{
+			name:            "ContinueOnError",
+			args:            []string{"--port", "0"},
+			continueOnError: true,
+			expectedError:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, tc.continueOnError)
+
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestRegisterFlagsValidation(t *testing.T) {
+	type spec struct {
+		Port int    `flag:"port,the port number" min:"1" max:"65535"`
+		Name string `flag:"name" nonempty:"true"`
+	}
+
+	s := &spec{Port: 8080}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "the port number\n"+
+		"data type:      int\n"+
+		"default value:  8080\n"+
+		"constraints:    min=1 max=65535",
+		fs.Lookup("port").Usage,
+	)
+
+	err = fs.Parse([]string{"-port", "0"})
+	assert.EqualError(t, err, `invalid value "0" for flag -port: invalid value 0 for flag port: must be at least 1`)
+
+	err = fs.Parse([]string{"-port", "9090"})
+	assert.NoError(t, err)
+
+	err = Validate(s)
+	assert.EqualError(t, err, "invalid value  for flag name: must not be empty")
+}
+
+type logLevel int
+
+func (l logLevel) String() string {
+	return [...]string{"debug", "info", "error"}[l]
+}
+
+func (l *logLevel) UnmarshalText(text []byte) error {
+	for i, name := range [...]string{"debug", "info", "error"} {
+		if string(text) == name {
+			*l = logLevel(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid log level: %s", text)
+}
+
+type hostPort struct {
+	Host string
+	Port int
+}
+
+func (h *hostPort) String() string {
+	return fmt.Sprintf("%s:%d", h.Host, h.Port)
+}
+
+func (h *hostPort) Set(val string) error {
+	i := strings.LastIndex(val, ":")
+	if i < 0 {
+		return fmt.Errorf("missing port: %s", val)
+	}
+
+	port, err := strconv.Atoi(val[i+1:])
+	if err != nil {
+		return err
+	}
+
+	h.Host, h.Port = val[:i], port
+	return nil
+}
+
+func TestPopulateArgsCustom(t *testing.T) {
+	type spec struct {
+		Level    logLevel            `flag:"level" default:"info"`
+		LevelPtr *logLevel           `flag:"level-ptr"`
+		Levels   []logLevel          `flag:"levels"`
+		Server   hostPort            `flag:"server"`
+		Peers    []*hostPort         `flag:"peer" mode:"append"`
+		ByName   map[string]logLevel `flag:"by-name"`
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expected      *spec
+	}{
+		{
+			name: "Default",
+			args: []string{},
+			expected: &spec{
+				Level: 1,
+			},
+		},
+		{
+			name: "OK",
+			args: []string{
+				"--level", "error",
+				"--level-ptr=debug",
+				"--levels", "debug,error",
+				"--server", "localhost:8080",
+				"--peer", "a:1,b:2", "--peer", "c:3",
+				"--by-name", "api=error",
+			},
+			expected: &spec{
+				Level:    2,
+				LevelPtr: new(logLevel),
+				Levels:   []logLevel{0, 2},
+				Server:   hostPort{"localhost", 8080},
+				Peers:    []*hostPort{{"a", 1}, {"b", 2}, {"c", 3}},
+				ByName:   map[string]logLevel{"api": 2},
+			},
+		},
+		{
+			name:          "InvalidTextUnmarshaler",
+			args:          []string{"--level", "trace"},
+			expectedError: "invalid log level: trace",
+		},
+		{
+			name:          "InvalidFlagValue",
+			args:          []string{"--server", "localhost"},
+			expectedError: "missing port: localhost",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, false)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsCustom(t *testing.T) {
+	type spec struct {
+		Level  logLevel `flag:"level,the log level"`
+		Server hostPort `flag:"server"`
+	}
+
+	s := &spec{Level: 1}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "the log level\n"+
+		"data type:      flagit.logLevel\n"+
+		"default value:  info",
+		fs.Lookup("level").Usage,
+	)
+
+	err = fs.Parse([]string{"-level", "error", "-server", "localhost:8080"})
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{Level: 2, Server: hostPort{"localhost", 8080}}, s)
+
+	err = fs.Parse([]string{"-level", "trace"})
+	assert.EqualError(t, err, `invalid value "trace" for flag -level: invalid log level: trace`)
+}
+
+type coordinate struct {
+	Lat, Long float64
+}
+
+func parseCoordinate(val string) (interface{}, error) {
+	var c coordinate
+	if _, err := fmt.Sscanf(val, "%f/%f", &c.Lat, &c.Long); err != nil {
+		return nil, fmt.Errorf("invalid coordinate: %s", val)
+	}
+	return c, nil
+}
+
+func TestPopulateArgsRegistered(t *testing.T) {
+	type spec struct {
+		Origin coordinate    `flag:"origin"`
+		Dest   *coordinate   `flag:"dest"`
+		Stops  []coordinate  `flag:"stops" sep:";"`
+		Marks  []*coordinate `flag:"mark" mode:"append"`
+	}
+
+	s := &spec{}
+	args := []string{"--origin", "1.5/2", "--dest=3/4", "--stops", "5/6;7/8", "--mark", "0/0", "--mark", "1/1"}
+
+	_, err := PopulateArgs(s, args, false)
+	assert.EqualError(t, err, "flag provided but not defined: --origin")
+
+	set.Register(reflect.TypeOf(coordinate{}), parseCoordinate)
+	defer set.Register(reflect.TypeOf(coordinate{}), nil)
+
+	assert.True(t, isTypeSupported(reflect.TypeOf(coordinate{})))
+	assert.True(t, isTypeSupported(reflect.TypeOf(&coordinate{})))
+	assert.True(t, isTypeSupported(reflect.TypeOf([]coordinate{})))
+
+	_, err = PopulateArgs(s, args, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{
+		Origin: coordinate{1.5, 2},
+		Dest:   &coordinate{3, 4},
+		Stops:  []coordinate{{5, 6}, {7, 8}},
+		Marks:  []*coordinate{{0, 0}, {1, 1}},
+	}, s)
+
+	_, err = PopulateArgs(s, []string{"--origin", "north"}, false)
+	assert.EqualError(t, err, "invalid coordinate: north")
+
+	t.Run("RegisterFlags", func(t *testing.T) {
+		s := &spec{Origin: coordinate{1, 2}}
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+
+		err := RegisterFlags(fs, s, false)
+		assert.NoError(t, err)
+		assert.Equal(t, "data type:      flagit.coordinate\n"+
+			"default value:  {1 2}",
+			fs.Lookup("origin").Usage,
+		)
+
+		err = fs.Parse([]string{"-origin", "3/4"})
+		assert.NoError(t, err)
+		assert.Equal(t, coordinate{3, 4}, s.Origin)
+	})
+}
+
+func TestPopulateArgsTime(t *testing.T) {
+	type spec struct {
+		Since   time.Time   `flag:"since" layout:"2006-01-02"`
+		Until   *time.Time  `flag:"until" tz:"UTC"`
+		Windows []time.Time `flag:"window" mode:"append"`
+		Created time.Time   `flag:"created" default:"2020-01-01T00:00:00Z"`
+	}
+
+	est := time.FixedZone("EST", -5*60*60)
+
+	tests := []struct {
+		name          string
+		args          []string
+		opts          []Option
+		expectedError string
+		expected      *spec
+	}{
+		{
+			name: "Default",
+			args: []string{},
+			expected: &spec{
+				Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "OK",
+			args: []string{
+				"--since", "2020-05-01",
+				"--until", "2020-05-02T10:00:00Z",
+				"--window", "1588291200", "--window", "2020-05-02T00:00:00Z",
+			},
+			expected: &spec{
+				Since:   time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+				Until:   func() *time.Time { t := time.Date(2020, 5, 2, 10, 0, 0, 0, time.UTC); return &t }(),
+				Windows: []time.Time{time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC)},
+				Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Location",
+			args: []string{"--since", "2020-05-01", "--until", "1588291200"},
+			opts: []Option{Location(est)},
+			expected: &spec{
+				Since:   time.Date(2020, 5, 1, 0, 0, 0, 0, est),
+				Until:   func() *time.Time { t := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC); return &t }(),
+				Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:          "Invalid",
+			args:          []string{"--since", "2020-05-01T00:00:00Z"},
+			expectedError: `parsing time "2020-05-01T00:00:00Z": extra text: "T00:00:00Z"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, false, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsTime(t *testing.T) {
+	type spec struct {
+		Since time.Time  `flag:"since,start of the window" layout:"2006-01-02"`
+		Until *time.Time `flag:"until"`
+	}
+
+	s := &spec{}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "start of the window\n"+
+		"data type:      time.Time\n"+
+		"default value:  0001-01-01 00:00:00 +0000 UTC\n"+
+		"layout:         2006-01-02",
+		fs.Lookup("since").Usage,
+	)
+	assert.Equal(t, "data type:      *time.Time\n"+
+		"default value:  <nil>\n"+
+		"layout:         2006-01-02T15:04:05Z07:00",
+		fs.Lookup("until").Usage,
+	)
+
+	err = fs.Parse([]string{"-since", "2020-05-01", "-until", "1588377600"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), s.Since)
+	assert.Equal(t, time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC), *s.Until)
+}
+
+func TestPopulateArgsNet(t *testing.T) {
+	type spec struct {
+		Bind   net.IP             `flag:"bind"`
+		Allow  []net.IPNet        `flag:"allow-cidr" mode:"append"`
+		MAC    net.HardwareAddr   `flag:"mac"`
+		MACs   []net.HardwareAddr `flag:"macs"`
+		Listen *net.TCPAddr       `flag:"listen" default:":8080"`
+		Peers  []net.TCPAddr      `flag:"peers"`
+		Routes []*net.IPNet       `flag:"routes"`
+		Relays []*net.TCPAddr     `flag:"relays"`
+	}
+
+	_, net1, _ := net.ParseCIDR("10.0.0.0/8")
+	_, net2, _ := net.ParseCIDR("192.168.0.0/16")
+	mac1, _ := net.ParseMAC("00:00:5e:00:53:01")
+	mac2, _ := net.ParseMAC("00:00:5e:00:53:02")
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expected      *spec
+	}{
+		{
+			name: "Default",
+			args: []string{},
+			expected: &spec{
+				Listen: &net.TCPAddr{Port: 8080},
+			},
+		},
+		{
+			name: "OK",
+			args: []string{
+				"--bind", "0.0.0.0",
+				"--allow-cidr", "10.0.0.0/8", "--allow-cidr", "192.168.0.0/16",
+				"--mac", "00:00:5e:00:53:01",
+				"--macs", "00:00:5e:00:53:01,00:00:5e:00:53:02",
+				"--listen", "127.0.0.1:9090",
+				"--peers", "10.0.0.1:7000,[::1]:7000",
+				"--routes", "10.0.0.0/8,192.168.0.0/16",
+				"--relays", "10.0.0.2:7000",
+			},
+			expected: &spec{
+				Bind:   net.ParseIP("0.0.0.0"),
+				Allow:  []net.IPNet{*net1, *net2},
+				MAC:    mac1,
+				MACs:   []net.HardwareAddr{mac1, mac2},
+				Listen: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9090},
+				Peers: []net.TCPAddr{
+					{IP: net.ParseIP("10.0.0.1"), Port: 7000},
+					{IP: net.ParseIP("::1"), Port: 7000},
+				},
+				Routes: []*net.IPNet{net1, net2},
+				Relays: []*net.TCPAddr{
+					{IP: net.ParseIP("10.0.0.2"), Port: 7000},
+				},
+			},
+		},
+		{
+			name:          "InvalidIP",
+			args:          []string{"--bind", "localhost"},
+			expectedError: "invalid IP address: localhost",
+		},
+		{
+			name:          "InvalidCIDR",
+			args:          []string{"--allow-cidr", "10.0.0.1"},
+			expectedError: "invalid CIDR address: 10.0.0.1",
+		},
+		{
+			name:          "InvalidMAC",
+			args:          []string{"--mac", "00:00:5e"},
+			expectedError: "address 00:00:5e: invalid MAC address",
+		},
+		{
+			name:          "InvalidTCPAddr",
+			args:          []string{"--listen", "localhost:9090"},
+			expectedError: "invalid IP address: localhost",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, false)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsNet(t *testing.T) {
+	type spec struct {
+		Allow  net.IPNet        `flag:"allow-cidr"`
+		MAC    net.HardwareAddr `flag:"mac"`
+		Listen *net.TCPAddr     `flag:"listen"`
+	}
+
+	_, allow, _ := net.ParseCIDR("10.0.0.0/8")
+	s := &spec{
+		Allow:  *allow,
+		Listen: &net.TCPAddr{Port: 8080},
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "data type:      net.IPNet\n"+
+		"default value:  10.0.0.0/8",
+		fs.Lookup("allow-cidr").Usage,
+	)
+	assert.Equal(t, "data type:      net.HardwareAddr\n"+
+		"default value:  ",
+		fs.Lookup("mac").Usage,
+	)
+	assert.Equal(t, "data type:      *net.TCPAddr\n"+
+		"default value:  :8080",
+		fs.Lookup("listen").Usage,
+	)
+
+	err = fs.Parse([]string{"-allow-cidr", "192.168.0.0/16", "-mac", "00:00:5e:00:53:01", "-listen", "127.0.0.1:9090"})
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.0.0/16", s.Allow.String())
+	assert.Equal(t, "00:00:5e:00:53:01", s.MAC.String())
+	assert.Equal(t, "127.0.0.1:9090", s.Listen.String())
+}
+
+func TestPopulateArgsUnit(t *testing.T) {
+	type spec struct {
+		MaxBody  int64   `flag:"max-body" unit:"bytes" default:"1MiB" max:"10MiB"`
+		Cache    *uint64 `flag:"cache" unit:"bytes"`
+		Requests int     `flag:"requests" unit:"si"`
+		Limits   []int   `flag:"limits" unit:"si"`
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expected      *spec
+	}{
+		{
+			name: "Default",
+			args: []string{},
+			expected: &spec{
+				MaxBody: 1048576,
+			},
+		},
+		{
+			name: "OK",
+			args: []string{
+				"--max-body", "512KiB",
+				"--cache", "1.5G",
+				"--requests", "10k",
+				"--limits", "1k,2.5k,100",
+			},
+			expected: &spec{
+				MaxBody:  524288,
+				Cache:    ptr.Uint64(1500000000),
+				Requests: 10000,
+				Limits:   []int{1000, 2500, 100},
+			},
+		},
+		{
+			name:          "InvalidUnit",
+			args:          []string{"--requests", "10KiB"},
+			expectedError: `invalid unit in value "10KiB": KiB`,
+		},
+		{
+			name:          "Validation",
+			args:          []string{"--max-body", "11MiB"},
+			expectedError: "invalid value 11534336 for flag max-body: must be at most 10MiB",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, false)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsUnit(t *testing.T) {
+	type spec struct {
+		MaxBody  int64   `flag:"max-body,the maximum size of request bodies" unit:"bytes"`
+		Cache    *uint64 `flag:"cache" unit:"bytes"`
+		Requests int     `flag:"requests" unit:"si"`
+	}
+
+	s := &spec{
+		MaxBody:  1048576,
+		Requests: 1500,
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "the maximum size of request bodies\n"+
+		"data type:      int64\n"+
+		"default value:  1MiB\n"+
+		"unit:           bytes",
+		fs.Lookup("max-body").Usage,
+	)
+	assert.Equal(t, "data type:      *uint64\n"+
+		"default value:  <nil>\n"+
+		"unit:           bytes",
+		fs.Lookup("cache").Usage,
+	)
+	assert.Equal(t, "data type:      int\n"+
+		"default value:  1.5k\n"+
+		"unit:           si",
+		fs.Lookup("requests").Usage,
+	)
+
+	err = fs.Parse([]string{"-max-body", "10MB", "-cache", "2GiB", "-requests", "2M"})
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{
+		MaxBody:  10000000,
+		Cache:    ptr.Uint64(2147483648),
+		Requests: 2000000,
+	}, s)
+}
+
+func TestPopulateArgsBase(t *testing.T) {
+	type spec struct {
+		Mask    uint32       `flag:"mask" base:"16" max:"ffff"`
+		Flags   []uint8      `flag:"flags" base:"2"`
+		Workers int          `flag:"workers"`
+		Mode    os.FileMode  `flag:"mode" default:"0644"`
+		DirMode *os.FileMode `flag:"dir-mode"`
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expected      *spec
+	}{
+		{
+			name: "Default",
+			args: []string{},
+			expected: &spec{
+				Mode: 0644,
+			},
+		},
+		{
+			name: "OK",
+			args: []string{
+				"--mask", "0xFF00",
+				"--flags", "1010,0b11",
+				"--workers", "1_000",
+				"--mode", "600",
+				"--dir-mode", "0o755",
+			},
+			expected: &spec{
+				Mask:    0xff00,
+				Flags:   []uint8{10, 3},
+				Workers: 1000,
+				Mode:    0600,
+				DirMode: func() *os.FileMode { m := os.FileMode(0755); return &m }(),
+			},
+		},
+		{
+			name:     "Prefix",
+			args:     []string{"--workers", "0x10"},
+			expected: &spec{Workers: 16, Mode: 0644},
+		},
+		{
+			name:          "InvalidDigit",
+			args:          []string{"--flags", "102"},
+			expectedError: `strconv.ParseUint: parsing "102": invalid syntax`,
+		},
+		{
+			name:          "Validation",
+			args:          []string{"--mask", "10000"},
+			expectedError: "invalid value 65536 for flag mask: must be at most ffff",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, false)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsBase(t *testing.T) {
+	type spec struct {
+		Mask uint32      `flag:"mask" base:"16"`
+		Mode os.FileMode `flag:"mode,the mode of new files"`
+	}
+
+	s := &spec{
+		Mask: 255,
+		Mode: 0644,
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "data type:      uint32\n"+
+		"default value:  0xff\n"+
+		"base:           16",
+		fs.Lookup("mask").Usage,
+	)
+	assert.Equal(t, "the mode of new files\n"+
+		"data type:      fs.FileMode\n"+
+		"default value:  0644",
+		fs.Lookup("mode").Usage,
+	)
+
+	err = fs.Parse([]string{"-mask", "ff00", "-mode", "0600"})
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{Mask: 0xff00, Mode: 0600}, s)
+}
+
+func TestPopulateArgsBoolVocabulary(t *testing.T) {
+	type spec struct {
+		TLS     bool  `flag:"tls"`
+		Verbose *bool `flag:"verbose" env:"FLAGIT_TEST_VERBOSE"`
+		Color   bool  `flag:"color" negatable:"true"`
+	}
+
+	os.Setenv("FLAGIT_TEST_VERBOSE", "yes")
+	defer os.Unsetenv("FLAGIT_TEST_VERBOSE")
+
+	s := &spec{}
+	_, err := PopulateArgs(s, []string{"--tls=on", "--no-color=Yes"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{TLS: true, Verbose: ptr.Bool(true), Color: false}, s)
+
+	set.SetBoolVocabulary(set.StrictBoolVocabulary)
+	defer set.SetBoolVocabulary(set.DefaultBoolVocabulary)
+
+	_, err = PopulateArgs(&spec{}, []string{"--tls=on"}, false)
+	assert.EqualError(t, err, `strconv.ParseBool: parsing "on": invalid syntax`)
+}
+
+func TestRegisterFlagsBoolVocabulary(t *testing.T) {
+	type spec struct {
+		TLS   bool `flag:"tls"`
+		Debug bool `flag:"debug"`
+	}
+
+	s := &spec{Debug: true}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+
+	err = fs.Parse([]string{"-tls=on", "-debug=disabled"})
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{TLS: true, Debug: false}, s)
+
+	err = fs.Parse([]string{"-tls=sure"})
+	assert.EqualError(t, err, `invalid boolean value "sure" for -tls: parse error`)
+}
+
+func TestPopulateArgsDuration(t *testing.T) {
+	type spec struct {
+		Retention time.Duration   `flag:"retention" default:"P30D" max:"52w"`
+		Timeout   *time.Duration  `flag:"timeout"`
+		Backoff   []time.Duration `flag:"backoff"`
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expected      *spec
+	}{
+		{
+			name: "Default",
+			args: []string{},
+			expected: &spec{
+				Retention: 30 * 24 * time.Hour,
+			},
+		},
+		{
+			name: "OK",
+			args: []string{
+				"--retention", "2w",
+				"--timeout", "PT1M30S",
+				"--backoff", "1s,1d12h,P1D",
+			},
+			expected: &spec{
+				Retention: 14 * 24 * time.Hour,
+				Timeout:   func() *time.Duration { d := 90 * time.Second; return &d }(),
+				Backoff:   []time.Duration{time.Second, 36 * time.Hour, 24 * time.Hour},
+			},
+		},
+		{
+			name:          "Invalid",
+			args:          []string{"--retention", "P1M"},
+			expectedError: `time: invalid duration "P1M"`,
+		},
+		{
+			name:          "Validation",
+			args:          []string{"--retention", "53w"},
+			expectedError: "invalid value 8904h0m0s for flag retention: must be at most 52w",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, false)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsDuration(t *testing.T) {
+	type spec struct {
+		Retention time.Duration   `flag:"retention"`
+		Timeout   *time.Duration  `flag:"timeout"`
+		Backoff   []time.Duration `flag:"backoff"`
+	}
+
+	timeout := 90 * time.Minute
+	s := &spec{
+		Retention: 30 * 24 * time.Hour,
+		Timeout:   &timeout,
+		Backoff:   []time.Duration{time.Second, 36 * time.Hour},
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "data type:      time.Duration\n"+
+		"default value:  30d",
+		fs.Lookup("retention").Usage,
+	)
+	assert.Equal(t, "data type:      *time.Duration\n"+
+		"default value:  1h30m",
+		fs.Lookup("timeout").Usage,
+	)
+	assert.Equal(t, "data type:      []time.Duration\n"+
+		"default value:  [1s 1d12h]\n"+
+		"separator:      ,",
+		fs.Lookup("backoff").Usage,
+	)
+
+	err = fs.Parse([]string{"-retention", "7d", "-timeout", "PT2H"})
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, s.Retention)
+	assert.Equal(t, 2*time.Hour, *s.Timeout)
+}
+
+type (
+	verbosity int
+	priority  uint8
+	style     string
+)
+
+func TestPopulateArgsEnum(t *testing.T) {
+	set.RegisterEnum(reflect.TypeOf(verbosity(0)),
+		set.Choice{Name: "quiet", Value: "0"},
+		set.Choice{Name: "normal", Value: "1"},
+		set.Choice{Name: "verbose", Value: "2"},
+	)
+	defer set.RegisterEnum(reflect.TypeOf(verbosity(0)))
+
+	type spec struct {
+		Level      int        `flag:"level" enum:"debug=0,info=1,warn=2,error=3" default:"info"`
+		Format     *string    `flag:"format" enum:"json,text"`
+		Levels     []uint8    `flag:"levels" enum:"debug=0,info=1,warn=2,error=3"`
+		Verbosity  verbosity  `flag:"verbosity"`
+		Quietness  *verbosity `flag:"quietness"`
+		Priority   *priority  `flag:"priority" enum:"low=1,high=9"`
+		Priorities []priority `flag:"priorities"`
+		Style      *style     `flag:"style" enum:"json,text"`
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expected      *spec
+	}{
+		{
+			name: "Default",
+			args: []string{},
+			expected: &spec{
+				Level: 1,
+			},
+		},
+		{
+			name: "OK",
+			args: []string{
+				"--level", "WARN",
+				"--format", "Text",
+				"--levels", "debug,error",
+				"--verbosity", "verbose",
+				"--quietness", "Quiet",
+				"--priority", "high",
+				"--priorities", "1,5",
+				"--style", "JSON",
+			},
+			expected: &spec{
+				Level:      2,
+				Format:     func() *string { s := "text"; return &s }(),
+				Levels:     []uint8{0, 3},
+				Verbosity:  2,
+				Quietness:  func() *verbosity { v := verbosity(0); return &v }(),
+				Priority:   func() *priority { p := priority(9); return &p }(),
+				Priorities: []priority{1, 5},
+				Style:      func() *style { s := style("json"); return &s }(),
+			},
+		},
+		{
+			name:          "Unknown",
+			args:          []string{"--level", "trace"},
+			expectedError: `invalid value "trace": must be one of debug, info, warn, error`,
+		},
+		{
+			name:          "UnknownRegistered",
+			args:          []string{"--verbosity", "1"},
+			expectedError: `invalid value "1": must be one of quiet, normal, verbose`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &spec{}
+			_, err := PopulateArgs(s, tc.args, false)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, s)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRegisterFlagsEnum(t *testing.T) {
+	type spec struct {
+		Level  int     `flag:"level" enum:"debug=0,info=1,warn=2,error=3"`
+		Format *string `flag:"format" enum:"json,text"`
+		Levels []uint8 `flag:"levels" enum:"debug=0,info=1,warn=2,error=3"`
+	}
+
+	s := &spec{
+		Level:  2,
+		Levels: []uint8{0, 3},
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "data type:      int\n"+
+		"default value:  warn\n"+
+		"choices:        debug, info, warn, error",
+		fs.Lookup("level").Usage,
+	)
+	assert.Equal(t, "data type:      *string\n"+
+		"default value:  <nil>\n"+
+		"choices:        json, text",
+		fs.Lookup("format").Usage,
+	)
+	assert.Equal(t, "data type:      []uint8\n"+
+		"default value:  [debug error]\n"+
+		"separator:      ,\n"+
+		"choices:        debug, info, warn, error",
+		fs.Lookup("levels").Usage,
+	)
+
+	err = fs.Parse([]string{"-level", "Error", "-format", "json"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, s.Level)
+	assert.Equal(t, "json", *s.Format)
+
+	err = fs.Parse([]string{"-level", "trace"})
+	assert.Error(t, err)
+}
+
+func TestRegisterFlagsCount(t *testing.T) {
+	type spec struct {
+		Verbosity int `flag:"verbose,the verbosity level" short:"v" mode:"count"`
+	}
+
+	s := &spec{}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+
+	err := RegisterFlags(fs, s, false)
+	assert.NoError(t, err)
+	assert.Contains(t, fs.Lookup("verbose").Usage, "repeatable:     yes (occurrences are counted)")
+
+	err = fs.Parse([]string{"-v", "-verbose", "--v", "input.txt"})
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{Verbosity: 3}, s)
+	assert.Equal(t, []string{"input.txt"}, fs.Args())
+}
+
+func TestPopulateArgsPositional(t *testing.T) {
+	type spec struct {
+		Verbose bool     `flag:"verbose" short:"v"`
+		Source  string   `arg:"0,required"`
+		Targets []string `arg:"rest"`
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		s := &spec{}
+		args, err := PopulateArgs(s, []string{"src", "-v", "dst1", "--", "-dst2"}, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{}, args)
+		assert.Equal(t, &spec{
+			Verbose: true,
+			Source:  "src",
+			Targets: []string{"dst1", "-dst2"},
+		}, s)
+	})
+
+	t.Run("MissingRequired", func(t *testing.T) {
+		args, err := PopulateArgs(&spec{}, []string{"-v"}, false)
+
+		assert.Nil(t, args)
+		assert.EqualError(t, err, "missing required argument: Source (position 0)")
+	})
+}
@@ -0,0 +1,371 @@
+package flagit
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/moorara/flagit/set"
+)
+
+// flagArg is a command-line argument that represents a flag.
+type flagArg struct {
+	dashes   int
+	name     string
+	value    string
+	hasValue bool
+}
+
+// String returns the flag argument without its value.
+func (a flagArg) String() string {
+	return strings.Repeat("-", a.dashes) + a.name
+}
+
+// parseFlagArg determines whether or not a command-line argument is a flag.
+// A flag starts with one or two dashes followed by a valid flag name and optionally an equal sign and a value.
+func parseFlagArg(arg string) (flagArg, bool) {
+	var a flagArg
+
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		a.dashes = 2
+	case strings.HasPrefix(arg, "-"):
+		a.dashes = 1
+	default:
+		return flagArg{}, false
+	}
+
+	a.name = arg[a.dashes:]
+	if i := strings.Index(a.name, "="); i >= 0 {
+		a.name, a.value, a.hasValue = a.name[:i], a.name[i+1:], true
+	}
+
+	if !flagNameRE.MatchString(a.name) {
+		return flagArg{}, false
+	}
+
+	return a, true
+}
+
+// isShortArg determines whether or not a command-line argument can be a group of short flags (-abc).
+func isShortArg(arg string) bool {
+	return len(arg) >= 2 && arg[0] == '-' && shortNameRE.MatchString(arg[1:2])
+}
+
+// parser is a single-pass tokenizer for command-line arguments.
+// Flag names are matched exactly and -x=v, -x v, --x=v, and --x v are all treated the same.
+// Boolean and counter flags only take a value through the equal sign, so the argument after them is never consumed as their value.
+//
+// Short flags follow the GNU getopt conventions.
+// Multiple short flags can be clustered together (-vxf out.txt) and the value of a short flag can be attached to it (-p8080).
+// A single-dash argument is first matched against the long flag names, so -verbose is still the same as --verbose.
+//
+// Negatable boolean flags can be set to false using their negative form (--no-<flag>).
+// Giving both the positive and the negative forms of a flag is an error.
+//
+// If there are subcommands, the first positional argument selects one of them.
+// The flags of the selected subcommand are then added to the flags already registered.
+type parser struct {
+	continueOnError bool
+	options         options
+	flags           map[string]fieldInfo
+	shorts          map[string]fieldInfo
+	negations       map[string]fieldInfo
+	fields          []fieldInfo
+	commands        map[string]*command
+	selected        []*command
+}
+
+func newParser(continueOnError bool) *parser {
+	return &parser{
+		continueOnError: continueOnError,
+		flags:           map[string]fieldInfo{},
+		shorts:          map[string]fieldInfo{},
+		negations:       map[string]fieldInfo{},
+	}
+}
+
+// exists determines whether or not a name is already registered with the parser.
+func (p *parser) exists(name string) bool {
+	_, longExists := p.flags[name]
+	_, shortExists := p.shorts[name]
+	_, negationExists := p.negations[name]
+
+	return longExists || shortExists || negationExists
+}
+
+// add registers a field with the parser.
+func (p *parser) add(f fieldInfo) error {
+	names := []string{f.flag}
+	if f.short != "" {
+		names = append(names, f.short)
+	}
+	if f.negatable {
+		names = append(names, f.negation())
+	}
+
+	for _, name := range names {
+		if p.exists(name) {
+			if p.continueOnError {
+				return nil
+			}
+			return fmt.Errorf("flag already registered: %s", name)
+		}
+	}
+
+	p.flags[f.flag] = f
+	p.fields = append(p.fields, f)
+	p.options.report.add(f)
+
+	if f.short != "" {
+		p.shorts[f.short] = f
+	}
+
+	if f.negatable {
+		p.negations[f.negation()] = f
+	}
+
+	return nil
+}
+
+// addStruct registers the flags and the subcommands of a struct value with the parser.
+// The subcommands registered previously are replaced, since only one subcommand can be selected at each level.
+func (p *parser) addStruct(v reflect.Value) error {
+	err := iterateOnFields("", v, p.continueOnError, func(f fieldInfo) error {
+		return p.add(p.options.field(f))
+	})
+
+	if err != nil {
+		return err
+	}
+
+	p.commands = map[string]*command{}
+
+	return iterateOnCommands(v, p.continueOnError, func(c *command) error {
+		if _, ok := p.commands[c.name]; ok {
+			if p.continueOnError {
+				return nil
+			}
+			return fmt.Errorf("command already registered: %s", c.name)
+		}
+
+		p.commands[c.name] = c
+
+		return nil
+	})
+}
+
+// selectCommand selects a subcommand and registers its flags and subcommands with the parser.
+func (p *parser) selectCommand(c *command) error {
+	p.selected = append(p.selected, c)
+	return p.addStruct(c.value())
+}
+
+// parse reads the flags from the given arguments and calls handle for every occurrence of a registered flag.
+// Parsing stops at the "--" terminator and all arguments that are neither flags nor flag values are returned as positional arguments.
+func (p *parser) parse(args []string, handle func(f fieldInfo, val string) error) ([]string, error) {
+	positionals := []string{}
+
+	// forms keeps track of the forms given for negatable flags to detect the conflicting ones.
+	forms := map[string]string{}
+	emit := func(f fieldInfo, form, val string) error {
+		if f.negatable {
+			if prev, ok := forms[f.flag]; ok && prev != form {
+				if p.continueOnError {
+					return nil
+				}
+				return fmt.Errorf("conflicting flags: %s and %s", prev, form)
+			}
+			forms[f.flag] = form
+		}
+
+		return handle(f, val)
+	}
+
+	positive := func(f fieldInfo, val string) error {
+		return emit(f, f.flag, val)
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			positionals = append(positionals, args[i+1:]...)
+			break
+		}
+
+		a, isFlag := parseFlagArg(arg)
+		if f, ok := p.flags[a.name]; isFlag && ok {
+			next, err := p.parseLong(args, i, a, f, positive)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+			continue
+		}
+
+		if f, ok := p.negations[a.name]; isFlag && ok {
+			val := "false"
+			if a.hasValue {
+				b, err := set.ParseBool(a.value)
+				if err != nil {
+					if p.continueOnError {
+						continue
+					}
+					return nil, fmt.Errorf("invalid boolean value %q for %s: %s", a.value, a, err)
+				}
+				val = strconv.FormatBool(!b)
+			}
+
+			if err := emit(f, a.name, val); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		isShort := isShortArg(arg)
+		if isShort {
+			if _, ok := p.shorts[arg[1:2]]; ok {
+				next, err := p.parseShorts(args, i, positive)
+				if err != nil {
+					return nil, err
+				}
+				i = next
+				continue
+			}
+		}
+
+		if isFlag || isShort {
+			if p.continueOnError || p.options.ignoreUnknown {
+				continue
+			}
+			if !isFlag {
+				a = flagArg{dashes: 1, name: arg[1:2]}
+			}
+			return nil, fmt.Errorf("flag provided but not defined: %s", a)
+		}
+
+		// The first positional argument selects a subcommand if there is any
+		if len(p.commands) > 0 {
+			if c, ok := p.commands[arg]; ok {
+				if err := p.selectCommand(c); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			if !p.continueOnError {
+				return nil, fmt.Errorf("unknown command: %s", arg)
+			}
+			p.commands = nil
+		}
+
+		positionals = append(positionals, arg)
+	}
+
+	return positionals, nil
+}
+
+// populate reads the flags from the given arguments and assigns their values to the fields.
+// It returns the positional arguments and the names of the flags seen in the arguments.
+func (p *parser) populate(args []string) ([]string, map[string]bool, error) {
+	seen := map[string]bool{}
+
+	positionals, err := p.parse(args, func(f fieldInfo, val string) error {
+		repeated := seen[f.flag]
+		seen[f.flag] = true
+
+		if _, err := f.setValue(val, repeated); err != nil {
+			if p.continueOnError {
+				return nil
+			}
+			return err
+		}
+
+		p.options.report.set(f, OriginFlag, val, repeated)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return positionals, seen, nil
+}
+
+// parseLong reads a single flag from args[i] that is matched by its full name.
+// It returns the index of the last argument consumed.
+func (p *parser) parseLong(args []string, i int, a flagArg, f fieldInfo, handle func(f fieldInfo, val string) error) (int, error) {
+	val := a.value
+	if !a.hasValue {
+		if f.isBoolFlag() {
+			val = "true"
+		} else if i+1 < len(args) && isValueArg(args[i+1]) {
+			i++
+			val = args[i]
+		} else {
+			if p.continueOnError {
+				return i, nil
+			}
+			return i, fmt.Errorf("flag needs an argument: %s", a)
+		}
+	}
+
+	return i, handle(f, val)
+}
+
+// parseShorts reads a group of clustered short flags from args[i].
+// It returns the index of the last argument consumed.
+func (p *parser) parseShorts(args []string, i int, handle func(f fieldInfo, val string) error) (int, error) {
+	rest := args[i][1:]
+
+	for rest != "" {
+		name := rest[:1]
+		rest = rest[1:]
+
+		f, ok := p.shorts[name]
+		if !ok {
+			if p.continueOnError || p.options.ignoreUnknown {
+				return i, nil
+			}
+			return i, fmt.Errorf("flag provided but not defined: -%s", name)
+		}
+
+		var val string
+		switch {
+		case f.isBoolFlag():
+			val = "true"
+			if strings.HasPrefix(rest, "=") {
+				val, rest = rest[1:], ""
+			}
+		case rest != "":
+			val, rest = strings.TrimPrefix(rest, "="), ""
+		case i+1 < len(args) && isValueArg(args[i+1]):
+			i++
+			val = args[i]
+		default:
+			if p.continueOnError {
+				return i, nil
+			}
+			return i, fmt.Errorf("flag needs an argument: -%s", name)
+		}
+
+		if err := handle(f, val); err != nil {
+			return i, err
+		}
+	}
+
+	return i, nil
+}
+
+// isValueArg determines whether or not a command-line argument can be the value of a preceding flag.
+func isValueArg(arg string) bool {
+	if arg == "--" {
+		return false
+	}
+
+	_, isFlag := parseFlagArg(arg)
+	return !isFlag && !isShortArg(arg)
+}
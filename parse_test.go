@@ -0,0 +1,504 @@
+package flagit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlagArg(t *testing.T) {
+	tests := []struct {
+		arg             string
+		expectedOK      bool
+		expectedFlagArg flagArg
+	}{
+		{"app", false, flagArg{}},
+		{"-", false, flagArg{}},
+		{"--", false, flagArg{}},
+		{"---name", false, flagArg{}},
+		{"-10", false, flagArg{}},
+		{"--=value", false, flagArg{}},
+		{"-name", true, flagArg{dashes: 1, name: "name"}},
+		{"--name", true, flagArg{dashes: 2, name: "name"}},
+		{"-name=", true, flagArg{dashes: 1, name: "name", value: "", hasValue: true}},
+		{"-name=value", true, flagArg{dashes: 1, name: "name", value: "value", hasValue: true}},
+		{"--name=value", true, flagArg{dashes: 2, name: "name", value: "value", hasValue: true}},
+		{"--name=a=b", true, flagArg{dashes: 2, name: "name", value: "a=b", hasValue: true}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.arg, func(t *testing.T) {
+			a, ok := parseFlagArg(tc.arg)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedFlagArg, a)
+		})
+	}
+}
+
+func TestIsShortArg(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected bool
+	}{
+		{"v", false},
+		{"-", false},
+		{"--", false},
+		{"--v", false},
+		{"-1", false},
+		{"-v", true},
+		{"-vxf", true},
+		{"-p8080", true},
+		{"-o/tmp/out.txt", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.arg, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isShortArg(tc.arg))
+		})
+	}
+}
+
+func TestParser(t *testing.T) {
+	flags := []fieldInfo{
+		{flag: "enabled", value: reflect.ValueOf(new(bool)).Elem()},
+		{flag: "verbose", value: reflect.ValueOf(new(*bool)).Elem()},
+		{flag: "number", value: reflect.ValueOf(new(int)).Elem()},
+		{flag: "text", value: reflect.ValueOf(new(string)).Elem()},
+		{flag: "name-list", value: reflect.ValueOf(new([]string)).Elem()},
+		{flag: "port", value: reflect.ValueOf(new(uint16)).Elem()},
+		{flag: "port-range", value: reflect.ValueOf(new(string)).Elem()},
+	}
+
+	tests := []struct {
+		name                string
+		args                []string
+		continueOnError     bool
+		expectedError       error
+		expectedValues      map[string]string
+		expectedPositionals []string
+	}{
+		{
+			name:                "NoArgs",
+			args:                []string{},
+			expectedValues:      map[string]string{},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "Enabled",
+			args:                []string{"-enabled"},
+			expectedValues:      map[string]string{"enabled": "true"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "EnabledFalse",
+			args:                []string{"--enabled=false"},
+			expectedValues:      map[string]string{"enabled": "false"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "BoolFollowedByValue",
+			args:                []string{"--enabled", "false", "--verbose", "input.txt"},
+			expectedValues:      map[string]string{"enabled": "true", "verbose": "true"},
+			expectedPositionals: []string{"false", "input.txt"},
+		},
+		{
+			name:                "BoolPointer",
+			args:                []string{"--verbose=false"},
+			expectedValues:      map[string]string{"verbose": "false"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "Number",
+			args:                []string{"-number", "-10"},
+			expectedValues:      map[string]string{"number": "-10"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "Text",
+			args:                []string{"-text=content", "--name-list", "alice,bob"},
+			expectedValues:      map[string]string{"text": "content", "name-list": "alice,bob"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "FlagFollowedByFlag",
+			args:                []string{"--enabled", "--text", "content"},
+			expectedValues:      map[string]string{"enabled": "true", "text": "content"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "AnchoredNames",
+			args:                []string{"--port-range=8000-9000", "-port", "8080"},
+			expectedValues:      map[string]string{"port": "8080", "port-range": "8000-9000"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "Positionals",
+			args:                []string{"first", "--text", "content", "second", "-", "third"},
+			expectedValues:      map[string]string{"text": "content"},
+			expectedPositionals: []string{"first", "second", "-", "third"},
+		},
+		{
+			name:                "Terminator",
+			args:                []string{"--text=content", "--", "--port=8080", "--"},
+			expectedValues:      map[string]string{"text": "content"},
+			expectedPositionals: []string{"--port=8080", "--"},
+		},
+		{
+			name:                "TerminatorAfterFlag",
+			args:                []string{"--enabled", "--", "file"},
+			expectedValues:      map[string]string{"enabled": "true"},
+			expectedPositionals: []string{"file"},
+		},
+		{
+			name:          "MissingValue",
+			args:          []string{"--text", "--enabled"},
+			expectedError: errors.New("flag needs an argument: --text"),
+		},
+		{
+			name:          "MissingValueAtEnd",
+			args:          []string{"-number"},
+			expectedError: errors.New("flag needs an argument: -number"),
+		},
+		{
+			name:                "MissingValue_ContinueOnError",
+			args:                []string{"--text", "--enabled"},
+			continueOnError:     true,
+			expectedValues:      map[string]string{"enabled": "true"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:          "UndefinedFlag",
+			args:          []string{"--transport=tcp"},
+			expectedError: errors.New("flag provided but not defined: --transport"),
+		},
+		{
+			name:                "UndefinedFlag_ContinueOnError",
+			args:                []string{"--transport=tcp", "-port", "8080"},
+			continueOnError:     true,
+			expectedValues:      map[string]string{"port": "8080"},
+			expectedPositionals: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newParser(tc.continueOnError)
+			for _, f := range flags {
+				assert.NoError(t, p.add(f))
+			}
+
+			values := map[string]string{}
+			positionals, err := p.parse(tc.args, func(f fieldInfo, val string) error {
+				values[f.flag] = val
+				return nil
+			})
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedValues, values)
+				assert.Equal(t, tc.expectedPositionals, positionals)
+			} else {
+				assert.Equal(t, tc.expectedError, err)
+				assert.Nil(t, positionals)
+			}
+		})
+	}
+}
+
+func TestParserShorts(t *testing.T) {
+	flags := []fieldInfo{
+		{flag: "verbose", short: "v", value: reflect.ValueOf(new(bool)).Elem()},
+		{flag: "extract", short: "x", value: reflect.ValueOf(new(*bool)).Elem()},
+		{flag: "file", short: "f", value: reflect.ValueOf(new(string)).Elem()},
+		{flag: "port", short: "p", value: reflect.ValueOf(new(uint16)).Elem()},
+		{flag: "output", short: "o", value: reflect.ValueOf(new(string)).Elem()},
+		{flag: "n", value: reflect.ValueOf(new(int)).Elem()},
+	}
+
+	tests := []struct {
+		name                string
+		args                []string
+		continueOnError     bool
+		expectedError       error
+		expectedValues      map[string]string
+		expectedPositionals []string
+	}{
+		{
+			name:                "Short",
+			args:                []string{"-v", "-p", "8080"},
+			expectedValues:      map[string]string{"verbose": "true", "port": "8080"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "LongWithSingleDash",
+			args:                []string{"-verbose", "-port=8080"},
+			expectedValues:      map[string]string{"verbose": "true", "port": "8080"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "Cluster",
+			args:                []string{"-vxf", "out.txt", "input.txt"},
+			expectedValues:      map[string]string{"verbose": "true", "extract": "true", "file": "out.txt"},
+			expectedPositionals: []string{"input.txt"},
+		},
+		{
+			name:                "AttachedValue",
+			args:                []string{"-p8080", "-o/tmp/out.txt", "-vf=in.txt"},
+			expectedValues:      map[string]string{"port": "8080", "output": "/tmp/out.txt", "verbose": "true", "file": "in.txt"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "BoolWithValue",
+			args:                []string{"-v=false", "-x", "file"},
+			expectedValues:      map[string]string{"verbose": "false", "extract": "true"},
+			expectedPositionals: []string{"file"},
+		},
+		{
+			name:                "LongNameOfOneLetter",
+			args:                []string{"-n", "10", "--n=20"},
+			expectedValues:      map[string]string{"n": "20"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:          "ShortWithDoubleDash",
+			args:          []string{"--v"},
+			expectedError: errors.New("flag provided but not defined: --v"),
+		},
+		{
+			name:          "UndefinedShort",
+			args:          []string{"-vz"},
+			expectedError: errors.New("flag provided but not defined: -z"),
+		},
+		{
+			name:          "UndefinedCluster",
+			args:          []string{"-zv"},
+			expectedError: errors.New("flag provided but not defined: -zv"),
+		},
+		{
+			name:          "UndefinedShortWithValue",
+			args:          []string{"-z/tmp"},
+			expectedError: errors.New("flag provided but not defined: -z"),
+		},
+		{
+			name:          "MissingValue",
+			args:          []string{"-vf"},
+			expectedError: errors.New("flag needs an argument: -f"),
+		},
+		{
+			name:          "MissingValueFollowedByFlag",
+			args:          []string{"-f", "-v"},
+			expectedError: errors.New("flag needs an argument: -f"),
+		},
+		{
+			name:                "ContinueOnError",
+			args:                []string{"-vz", "-f", "-p", "8080", "-xf"},
+			continueOnError:     true,
+			expectedValues:      map[string]string{"verbose": "true", "port": "8080", "extract": "true"},
+			expectedPositionals: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newParser(tc.continueOnError)
+			for _, f := range flags {
+				assert.NoError(t, p.add(f))
+			}
+
+			values := map[string]string{}
+			positionals, err := p.parse(tc.args, func(f fieldInfo, val string) error {
+				values[f.flag] = val
+				return nil
+			})
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedValues, values)
+				assert.Equal(t, tc.expectedPositionals, positionals)
+			} else {
+				assert.Equal(t, tc.expectedError, err)
+				assert.Nil(t, positionals)
+			}
+		})
+	}
+}
+
+func TestParserNegations(t *testing.T) {
+	flags := []fieldInfo{
+		{flag: "color", negatable: true, value: reflect.ValueOf(new(bool)).Elem()},
+		{flag: "verbose", short: "v", negatable: true, value: reflect.ValueOf(new(*bool)).Elem()},
+		{flag: "debug", value: reflect.ValueOf(new(bool)).Elem()},
+	}
+
+	tests := []struct {
+		name                string
+		args                []string
+		continueOnError     bool
+		expectedError       error
+		expectedValues      map[string]string
+		expectedPositionals []string
+	}{
+		{
+			name:                "Negation",
+			args:                []string{"--no-color", "-no-verbose", "file"},
+			expectedValues:      map[string]string{"color": "false", "verbose": "false"},
+			expectedPositionals: []string{"file"},
+		},
+		{
+			name:                "NegationWithValue",
+			args:                []string{"--no-color=false", "--no-verbose=true"},
+			expectedValues:      map[string]string{"color": "true", "verbose": "false"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:                "RepeatedForm",
+			args:                []string{"--no-color", "--no-color", "-v", "--verbose"},
+			expectedValues:      map[string]string{"color": "false", "verbose": "true"},
+			expectedPositionals: []string{},
+		},
+		{
+			name:          "NotNegatable",
+			args:          []string{"--no-debug"},
+			expectedError: errors.New("flag provided but not defined: --no-debug"),
+		},
+		{
+			name:          "InvalidValue",
+			args:          []string{"--no-color=invalid"},
+			expectedError: errors.New(`invalid boolean value "invalid" for --no-color: strconv.ParseBool: parsing "invalid": invalid syntax`),
+		},
+		{
+			name:          "Conflict",
+			args:          []string{"--color", "--no-color"},
+			expectedError: errors.New("conflicting flags: color and no-color"),
+		},
+		{
+			name:          "ConflictWithShort",
+			args:          []string{"--no-verbose", "-v"},
+			expectedError: errors.New("conflicting flags: no-verbose and verbose"),
+		},
+		{
+			name:                "ContinueOnError",
+			args:                []string{"--no-color=invalid", "--no-verbose", "-v"},
+			continueOnError:     true,
+			expectedValues:      map[string]string{"verbose": "false"},
+			expectedPositionals: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newParser(tc.continueOnError)
+			for _, f := range flags {
+				assert.NoError(t, p.add(f))
+			}
+
+			values := map[string]string{}
+			positionals, err := p.parse(tc.args, func(f fieldInfo, val string) error {
+				values[f.flag] = val
+				return nil
+			})
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedValues, values)
+				assert.Equal(t, tc.expectedPositionals, positionals)
+			} else {
+				assert.Equal(t, tc.expectedError, err)
+				assert.Nil(t, positionals)
+			}
+		})
+	}
+}
+
+func TestParserAdd(t *testing.T) {
+	tests := []struct {
+		name            string
+		fields          []fieldInfo
+		continueOnError bool
+		expectedError   error
+	}{
+		{
+			name: "OK",
+			fields: []fieldInfo{
+				{flag: "name", short: "n"},
+				{flag: "verbose", short: "v"},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "DuplicateFlag",
+			fields: []fieldInfo{
+				{flag: "name"},
+				{flag: "name"},
+			},
+			expectedError: errors.New("flag already registered: name"),
+		},
+		{
+			name: "DuplicateShort",
+			fields: []fieldInfo{
+				{flag: "name", short: "n"},
+				{flag: "number", short: "n"},
+			},
+			expectedError: errors.New("flag already registered: n"),
+		},
+		{
+			name: "ShortConflictsWithFlag",
+			fields: []fieldInfo{
+				{flag: "n"},
+				{flag: "name", short: "n"},
+			},
+			expectedError: errors.New("flag already registered: n"),
+		},
+		{
+			name: "FlagConflictsWithShort",
+			fields: []fieldInfo{
+				{flag: "name", short: "n"},
+				{flag: "n"},
+			},
+			expectedError: errors.New("flag already registered: n"),
+		},
+		{
+			name: "NegationConflictsWithFlag",
+			fields: []fieldInfo{
+				{flag: "no-color"},
+				{flag: "color", negatable: true},
+			},
+			expectedError: errors.New("flag already registered: no-color"),
+		},
+		{
+			name: "FlagConflictsWithNegation",
+			fields: []fieldInfo{
+				{flag: "color", negatable: true},
+				{flag: "no-color"},
+			},
+			expectedError: errors.New("flag already registered: no-color"),
+		},
+		{
+			name: "ContinueOnError",
+			fields: []fieldInfo{
+				{flag: "name", short: "n"},
+				{flag: "name"},
+				{flag: "number", short: "n"},
+			},
+			continueOnError: true,
+			expectedError:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			p := newParser(tc.continueOnError)
+			for _, f := range tc.fields {
+				if err = p.add(f); err != nil {
+					break
+				}
+			}
+
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
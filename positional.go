@@ -0,0 +1,219 @@
+package flagit
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moorara/flagit/set"
+)
+
+const (
+	// argRest is the position for binding all remaining positional arguments to a slice field.
+	argRest = "rest"
+	// argRequired is the option for positional arguments that must be provided.
+	argRequired = "required"
+	// argOptional is the option for positional arguments that can be omitted (default).
+	argOptional = "optional"
+)
+
+// argInfo is a struct field that is bound to one or more positional arguments.
+type argInfo struct {
+	value    reflect.Value
+	name     string
+	index    int
+	rest     bool
+	required bool
+	sep      string
+	valueTags
+}
+
+// String returns the name of the positional argument for error messages.
+func (a argInfo) String() string {
+	if a.rest {
+		return fmt.Sprintf("%s (rest)", a.name)
+	}
+	return fmt.Sprintf("%s (position %d)", a.name, a.index)
+}
+
+func iterateOnArgs(vStruct reflect.Value, continueOnError bool, handle func(a argInfo) error) error {
+	// Iterate over struct fields
+	for i := 0; i < vStruct.NumField(); i++ {
+		v := vStruct.Field(i)
+		t := v.Type()
+		f := vStruct.Type().Field(i)
+
+		// Positional arguments of subcommands are iterated separately
+		if f.Tag.Get(cmdTag) != "" {
+			continue
+		}
+
+		// Recursively, iterate on nested structs
+		if isNestedStruct(t) {
+			if err := iterateOnArgs(v, continueOnError, handle); err != nil {
+				return err
+			}
+		}
+
+		// Skip unexported and unsupported fields
+		if !v.CanSet() || !isTypeSupported(t) {
+			continue
+		}
+
+		// `arg:"..."`
+		val := f.Tag.Get(argTag)
+		if val == "" {
+			continue
+		}
+
+		a := argInfo{
+			value: v,
+			name:  f.Name,
+		}
+
+		valid := true
+		subs := strings.Split(val, ",")
+
+		if subs[0] == argRest {
+			a.rest = true
+			valid = t.Kind() == reflect.Slice
+		} else if index, err := strconv.Atoi(subs[0]); err == nil && index >= 0 {
+			a.index = index
+		} else {
+			valid = false
+		}
+
+		for _, opt := range subs[1:] {
+			switch opt {
+			case argRequired:
+				a.required = true
+			case argOptional:
+				a.required = false
+			default:
+				valid = false
+			}
+		}
+
+		if !valid {
+			if continueOnError {
+				continue
+			}
+			return fmt.Errorf("invalid argument for field %s: %s", f.Name, val)
+		}
+
+		// `sep:"..."`
+		if a.sep = f.Tag.Get(sepTag); a.sep == "" {
+			a.sep = ","
+		}
+
+		// `kvsep:"..."`, `layout:"..."`, `tz:"..."`, `unit:"..."`, `base:"..."`, and `enum:"..."`
+		vt, err := parseValueTags(f.Tag, t, "argument "+f.Name)
+		if err != nil {
+			if continueOnError {
+				continue
+			}
+			return err
+		}
+		a.valueTags = vt
+
+		if err := handle(a); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bindArgs assigns the positional arguments to the struct fields that have the arg tag.
+// It returns the positional arguments that are not bound to any field.
+func bindArgs(vStruct reflect.Value, positionals []string, continueOnError bool, o options) ([]string, error) {
+	var rest *argInfo
+	args := []argInfo{}
+
+	err := iterateOnArgs(vStruct, continueOnError, func(a argInfo) error {
+		if o.location != nil && a.location == nil {
+			a.location = o.location
+		}
+
+		if a.rest {
+			if rest != nil {
+				if continueOnError {
+					return nil
+				}
+				return fmt.Errorf("argument already bound: %s and %s", rest, a)
+			}
+			rest = &a
+			return nil
+		}
+
+		for _, b := range args {
+			if b.index == a.index {
+				if continueOnError {
+					return nil
+				}
+				return fmt.Errorf("argument already bound: %s and %s", b, a)
+			}
+		}
+		args = append(args, a)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(args, func(i, j int) bool {
+		return args[i].index < args[j].index
+	})
+
+	// next is the position of the first positional argument after the ones bound by position
+	var next int
+	bound := map[int]bool{}
+
+	for _, a := range args {
+		next = a.index + 1
+
+		if a.index >= len(positionals) {
+			if a.required && !continueOnError {
+				return nil, fmt.Errorf("missing required argument: %s", a)
+			}
+			continue
+		}
+
+		bound[a.index] = true
+		if _, err := set.Value(a.value, a.sep, positionals[a.index], a.setOptions()...); err != nil {
+			if continueOnError {
+				continue
+			}
+			return nil, fmt.Errorf("invalid value %q for argument %s: %s", positionals[a.index], a, err)
+		}
+	}
+
+	if rest != nil {
+		if next >= len(positionals) {
+			if rest.required && !continueOnError {
+				return nil, fmt.Errorf("missing required argument: %s", rest)
+			}
+		} else {
+			for i := next; i < len(positionals); i++ {
+				bound[i] = true
+			}
+
+			if _, err := set.Values(rest.value, positionals[next:], rest.setOptions()...); err != nil && !continueOnError {
+				return nil, fmt.Errorf("invalid value %q for argument %s: %s", strings.Join(positionals[next:], " "), rest, err)
+			}
+		}
+	}
+
+	remaining := []string{}
+	for i, arg := range positionals {
+		if !bound[i] {
+			remaining = append(remaining, arg)
+		}
+	}
+
+	return remaining, nil
+}
@@ -0,0 +1,310 @@
+package flagit
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgInfoString(t *testing.T) {
+	assert.Equal(t, "Input (position 0)", argInfo{name: "Input", index: 0}.String())
+	assert.Equal(t, "Files (rest)", argInfo{name: "Files", rest: true}.String())
+}
+
+func TestIterateOnArgs(t *testing.T) {
+	tests := []struct {
+		name              string
+		s                 interface{}
+		continueOnError   bool
+		expectedError     error
+		expectedNames     []string
+		expectedIndices   []int
+		expectedRests     []bool
+		expectedRequireds []bool
+	}{
+		{
+			name: "InvalidPosition",
+			s: &struct {
+				Input string `arg:"first"`
+			}{},
+			expectedError:     errors.New("invalid argument for field Input: first"),
+			expectedNames:     []string{},
+			expectedIndices:   []int{},
+			expectedRests:     []bool{},
+			expectedRequireds: []bool{},
+		},
+		{
+			name: "NegativePosition",
+			s: &struct {
+				Input string `arg:"-1"`
+			}{},
+			expectedError:     errors.New("invalid argument for field Input: -1"),
+			expectedNames:     []string{},
+			expectedIndices:   []int{},
+			expectedRests:     []bool{},
+			expectedRequireds: []bool{},
+		},
+		{
+			name: "InvalidOption",
+			s: &struct {
+				Input string `arg:"0,mandatory"`
+			}{},
+			expectedError:     errors.New("invalid argument for field Input: 0,mandatory"),
+			expectedNames:     []string{},
+			expectedIndices:   []int{},
+			expectedRests:     []bool{},
+			expectedRequireds: []bool{},
+		},
+		{
+			name: "NonSliceRest",
+			s: &struct {
+				Files string `arg:"rest"`
+			}{},
+			expectedError:     errors.New("invalid argument for field Files: rest"),
+			expectedNames:     []string{},
+			expectedIndices:   []int{},
+			expectedRests:     []bool{},
+			expectedRequireds: []bool{},
+		},
+		{
+			name: "InvalidTZ",
+			s: &struct {
+				Since time.Time `arg:"0" tz:"Mars/Olympus"`
+			}{},
+			expectedError:     errors.New("invalid tz for argument Since: Mars/Olympus"),
+			expectedNames:     []string{},
+			expectedIndices:   []int{},
+			expectedRests:     []bool{},
+			expectedRequireds: []bool{},
+		},
+		{
+			name: "InvalidEnum",
+			s: &struct {
+				Level int `arg:"0" enum:"debug=0,debug=1"`
+			}{},
+			expectedError:     errors.New("invalid enum for argument Level: duplicate choice: debug"),
+			expectedNames:     []string{},
+			expectedIndices:   []int{},
+			expectedRests:     []bool{},
+			expectedRequireds: []bool{},
+		},
+		{
+			name: "ContinueOnError",
+			s: &struct {
+				Input string `arg:"first"`
+				Files string `arg:"rest"`
+			}{},
+			continueOnError:   true,
+			expectedError:     nil,
+			expectedNames:     []string{},
+			expectedIndices:   []int{},
+			expectedRests:     []bool{},
+			expectedRequireds: []bool{},
+		},
+		{
+			name: "OK",
+			s: &struct {
+				unexported string `arg:"3"`
+				Verbose    bool   `flag:"verbose"`
+				Input      string `arg:"0,required"`
+				Group      struct {
+					Output *url.URL `arg:"1,optional"`
+				}
+				Files []string `arg:"rest"`
+			}{},
+			expectedError:     nil,
+			expectedNames:     []string{"Input", "Output", "Files"},
+			expectedIndices:   []int{0, 1, 0},
+			expectedRests:     []bool{false, false, true},
+			expectedRequireds: []bool{true, false, false},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			names := []string{}
+			indices := []int{}
+			rests := []bool{}
+			requireds := []bool{}
+
+			vStruct, err := validateStruct(tc.s)
+			assert.NoError(t, err)
+
+			err = iterateOnArgs(vStruct, tc.continueOnError, func(a argInfo) error {
+				names = append(names, a.name)
+				indices = append(indices, a.index)
+				rests = append(rests, a.rest)
+				requireds = append(requireds, a.required)
+				return nil
+			})
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedNames, names)
+			assert.Equal(t, tc.expectedIndices, indices)
+			assert.Equal(t, tc.expectedRests, rests)
+			assert.Equal(t, tc.expectedRequireds, requireds)
+		})
+	}
+}
+
+func TestBindArgs(t *testing.T) {
+	type copySpec struct {
+		Source string   `arg:"0,required"`
+		Target *string  `arg:"1"`
+		Ports  []uint16 `arg:"2"`
+	}
+
+	type restSpec struct {
+		Command string   `arg:"0,required"`
+		Args    []string `arg:"rest,required"`
+	}
+
+	type sumSpec struct {
+		Numbers []int `arg:"rest"`
+	}
+
+	type tagSpec struct {
+		Since time.Time `arg:"0" layout:"2006-01-02"`
+		Level int       `arg:"1" enum:"debug=0,info=1"`
+		Size  uint64    `arg:"2" unit:"bytes"`
+		Masks []uint8   `arg:"rest" base:"16"`
+	}
+
+	target := "out.txt"
+
+	tests := []struct {
+		name            string
+		s               interface{}
+		positionals     []string
+		continueOnError bool
+		expectedError   error
+		expectedArgs    []string
+		expected        interface{}
+	}{
+		{
+			name:          "MissingRequired",
+			s:             &copySpec{},
+			positionals:   []string{},
+			expectedError: errors.New("missing required argument: Source (position 0)"),
+		},
+		{
+			name:          "MissingRequiredRest",
+			s:             &restSpec{},
+			positionals:   []string{"run"},
+			expectedError: errors.New("missing required argument: Args (rest)"),
+		},
+		{
+			name:          "InvalidValue",
+			s:             &copySpec{},
+			positionals:   []string{"in.txt", "out.txt", "80,invalid"},
+			expectedError: errors.New(`invalid value "80,invalid" for argument Ports (position 2): strconv.ParseUint: parsing "invalid": invalid syntax`),
+		},
+		{
+			name:          "InvalidRestValue",
+			s:             &sumSpec{},
+			positionals:   []string{"1", "two"},
+			expectedError: errors.New(`invalid value "1 two" for argument Numbers (rest): strconv.ParseInt: parsing "two": invalid syntax`),
+		},
+		{
+			name: "DuplicatePosition",
+			s: &struct {
+				A string `arg:"0"`
+				B string `arg:"0"`
+			}{},
+			positionals:   []string{"a"},
+			expectedError: errors.New("argument already bound: A (position 0) and B (position 0)"),
+		},
+		{
+			name: "DuplicateRest",
+			s: &struct {
+				A []string `arg:"rest"`
+				B []string `arg:"rest"`
+			}{},
+			positionals:   []string{"a"},
+			expectedError: errors.New("argument already bound: A (rest) and B (rest)"),
+		},
+		{
+			name:         "OptionalOmitted",
+			s:            &copySpec{},
+			positionals:  []string{"in.txt"},
+			expectedArgs: []string{},
+			expected:     &copySpec{Source: "in.txt"},
+		},
+		{
+			name:         "AllBound",
+			s:            &copySpec{},
+			positionals:  []string{"in.txt", "out.txt", "80,443", "extra"},
+			expectedArgs: []string{"extra"},
+			expected: &copySpec{
+				Source: "in.txt",
+				Target: &target,
+				Ports:  []uint16{80, 443},
+			},
+		},
+		{
+			name:         "Rest",
+			s:            &restSpec{},
+			positionals:  []string{"echo", "hello,world", "!"},
+			expectedArgs: []string{},
+			expected: &restSpec{
+				Command: "echo",
+				Args:    []string{"hello,world", "!"},
+			},
+		},
+		{
+			name:         "RestOnly",
+			s:            &sumSpec{},
+			positionals:  []string{"1", "2", "3"},
+			expectedArgs: []string{},
+			expected: &sumSpec{
+				Numbers: []int{1, 2, 3},
+			},
+		},
+		{
+			name:         "Tags",
+			s:            &tagSpec{},
+			positionals:  []string{"2024-01-02", "INFO", "1KiB", "ff", "0x0f"},
+			expectedArgs: []string{},
+			expected: &tagSpec{
+				Since: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				Level: 1,
+				Size:  1024,
+				Masks: []uint8{255, 15},
+			},
+		},
+		{
+			name:          "InvalidEnumValue",
+			s:             &tagSpec{},
+			positionals:   []string{"2024-01-02", "trace"},
+			expectedError: errors.New(`invalid value "trace" for argument Level (position 1): invalid value "trace": must be one of debug, info`),
+		},
+		{
+			name:            "ContinueOnError",
+			s:               &copySpec{},
+			positionals:     []string{},
+			continueOnError: true,
+			expectedArgs:    []string{},
+			expected:        &copySpec{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vStruct := reflect.ValueOf(tc.s).Elem()
+			args, err := bindArgs(vStruct, tc.positionals, tc.continueOnError, options{})
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedArgs, args)
+				assert.Equal(t, tc.expected, tc.s)
+			} else {
+				assert.Equal(t, tc.expectedError, err)
+				assert.Nil(t, args)
+			}
+		})
+	}
+}
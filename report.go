@@ -0,0 +1,191 @@
+package flagit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Origin is where the value of a struct field came from.
+type Origin string
+
+const (
+	// OriginNone is for the struct fields that still have the zero value.
+	OriginNone Origin = ""
+	// OriginProgram is for the values set on the struct fields before populating them.
+	OriginProgram Origin = "programmatic"
+	// OriginDefault is for the values read from the default tags.
+	OriginDefault Origin = "default"
+	// OriginFile is for the values read from a config file (MapSource).
+	OriginFile Origin = "file"
+	// OriginEnv is for the values read from the environment variables.
+	OriginEnv Origin = "env"
+	// OriginFlag is for the values read from the command-line flags.
+	OriginFlag Origin = "flag"
+	// OriginSource is for the values read from a custom Source.
+	OriginSource Origin = "source"
+)
+
+// Entry is the provenance of the value of a single struct field.
+type Entry struct {
+	// Field is the name of the struct field.
+	Field string
+	// Flag is the full name of the flag (including the prefixes of nested structs).
+	Flag string
+	// Origin is where the current value of the field came from.
+	Origin Origin
+	// Raw is the list of the string values given by the origin (one per occurrence of a repeated flag).
+	Raw []string
+	// Choices is the list of the valid names for an enum field (see set.Enum).
+	// It can be used for listing the choices in shell completions. It is nil for the other fields.
+	Choices []string
+
+	value reflect.Value
+}
+
+// Value returns the current value of the struct field.
+func (e Entry) Value() interface{} {
+	return e.value.Interface()
+}
+
+// Report records where the value of each struct field came from.
+// The zero value is an empty report ready to use.
+type Report struct {
+	entries []*Entry
+	index   map[string]*Entry
+}
+
+// Record records the provenance of the values into the given report.
+func Record(r *Report) Option {
+	return func(o *options) {
+		o.report = r
+	}
+}
+
+// add adds an entry for a field with the current value of the field.
+// If the field is already in the report, the existing entry is kept.
+func (r *Report) add(f fieldInfo) {
+	if r == nil {
+		return
+	}
+
+	if r.index == nil {
+		r.index = map[string]*Entry{}
+	}
+
+	if _, ok := r.index[f.flag]; ok {
+		return
+	}
+
+	e := &Entry{
+		Field:  f.name,
+		Flag:   f.flag,
+		Origin: OriginNone,
+		Raw:    []string{},
+		value:  f.value,
+	}
+
+	for _, c := range f.enum {
+		e.Choices = append(e.Choices, c.Name)
+	}
+
+	if !f.value.IsZero() {
+		e.Origin = OriginProgram
+	}
+
+	r.entries = append(r.entries, e)
+	r.index[f.flag] = e
+}
+
+// set records a value for a field.
+// For a repeated flag, the value is added to the values of the previous occurrences.
+func (r *Report) set(f fieldInfo, origin Origin, raw string, repeated bool) {
+	if r == nil {
+		return
+	}
+
+	r.add(f)
+	e := r.index[f.flag]
+
+	if !repeated || e.Origin != origin {
+		e.Raw = []string{}
+	}
+
+	e.Origin = origin
+	e.Raw = append(e.Raw, raw)
+}
+
+// Lookup returns the entry for a flag by its full name (including the prefixes of nested structs).
+func (r *Report) Lookup(flag string) (Entry, bool) {
+	if r == nil || r.index == nil {
+		return Entry{}, false
+	}
+
+	e, ok := r.index[flag]
+	if !ok {
+		return Entry{}, false
+	}
+
+	return *e, true
+}
+
+// Entries returns the entries for all struct fields in the order of the fields.
+func (r *Report) Entries() []Entry {
+	entries := []Entry{}
+	if r == nil {
+		return entries
+	}
+
+	for _, e := range r.entries {
+		entries = append(entries, *e)
+	}
+
+	return entries
+}
+
+// Print writes the report as a table of flags, values, origins, and raw values.
+// It can be used for implementing a flag that prints the resolved configuration (--print-config).
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tVALUE\tORIGIN\tRAW")
+
+	for _, e := range r.Entries() {
+		origin := string(e.Origin)
+		if origin == "" {
+			origin = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%v\t%s\t%s\n", e.Flag, formatValue(e.value), origin, strings.Join(e.Raw, " "))
+	}
+
+	return tw.Flush()
+}
+
+// String returns the report as a table (see Print).
+func (r *Report) String() string {
+	var buf bytes.Buffer
+	_ = r.Print(&buf)
+	return buf.String()
+}
+
+// formatValue formats a value for printing, so that the pointers are printed by the values they point to.
+func formatValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		v = v.Elem()
+	}
+
+	// url.URL and regexp.Regexp have String methods on their pointer types
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		if s, ok := v.Addr().Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+	}
+
+	return v.Interface()
+}
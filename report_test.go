@@ -0,0 +1,137 @@
+package flagit
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moorara/flagit/ptr"
+	"github.com/moorara/flagit/set"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	port, timeout := 8080, time.Duration(0)
+	portField := fieldInfo{value: reflect.ValueOf(&port).Elem(), name: "Port", flag: "port"}
+	timeoutField := fieldInfo{value: reflect.ValueOf(&timeout).Elem(), name: "Timeout", flag: "timeout"}
+
+	level := 1
+	levelField := fieldInfo{
+		value: reflect.ValueOf(&level).Elem(),
+		name:  "Level",
+		flag:  "level",
+		valueTags: valueTags{
+			enum: []set.Choice{{Name: "debug", Value: "0"}, {Name: "info", Value: "1"}},
+		},
+	}
+
+	t.Run("Nil", func(t *testing.T) {
+		var r *Report
+		r.add(portField)
+		r.set(portField, OriginFlag, "9090", false)
+
+		_, ok := r.Lookup("port")
+		assert.False(t, ok)
+		assert.Equal(t, []Entry{}, r.Entries())
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		r := &Report{}
+
+		_, ok := r.Lookup("port")
+		assert.False(t, ok)
+
+		r.add(portField)
+		r.add(timeoutField)
+		r.add(portField)
+
+		e, ok := r.Lookup("port")
+		assert.True(t, ok)
+		assert.Equal(t, "Port", e.Field)
+		assert.Equal(t, "port", e.Flag)
+		assert.Equal(t, OriginProgram, e.Origin)
+		assert.Equal(t, []string{}, e.Raw)
+		assert.Nil(t, e.Choices)
+		assert.Equal(t, 8080, e.Value())
+
+		e, ok = r.Lookup("timeout")
+		assert.True(t, ok)
+		assert.Equal(t, OriginNone, e.Origin)
+
+		r.add(levelField)
+		e, ok = r.Lookup("level")
+		assert.True(t, ok)
+		assert.Equal(t, []string{"debug", "info"}, e.Choices)
+
+		timeout = 30 * time.Second
+		r.set(timeoutField, OriginDefault, "30s", false)
+		timeout = 5 * time.Second
+		r.set(timeoutField, OriginEnv, "5s", false)
+
+		port = 9090
+		r.set(portField, OriginFlag, "9000", false)
+		r.set(portField, OriginFlag, "9090", true)
+
+		entries := r.Entries()
+		assert.Len(t, entries, 3)
+
+		assert.Equal(t, "port", entries[0].Flag)
+		assert.Equal(t, OriginFlag, entries[0].Origin)
+		assert.Equal(t, []string{"9000", "9090"}, entries[0].Raw)
+		assert.Equal(t, 9090, entries[0].Value())
+
+		assert.Equal(t, "timeout", entries[1].Flag)
+		assert.Equal(t, OriginEnv, entries[1].Origin)
+		assert.Equal(t, []string{"5s"}, entries[1].Raw)
+		assert.Equal(t, 5*time.Second, entries[1].Value())
+	})
+}
+
+func TestReportPrint(t *testing.T) {
+	port, timeout, name := 8080, 5*time.Second, ""
+	r := &Report{}
+	r.add(fieldInfo{value: reflect.ValueOf(&port).Elem(), flag: "port"})
+	r.add(fieldInfo{value: reflect.ValueOf(&timeout).Elem(), flag: "timeout"})
+	r.add(fieldInfo{value: reflect.ValueOf(&name).Elem(), flag: "name"})
+	r.set(fieldInfo{value: reflect.ValueOf(&timeout).Elem(), flag: "timeout"}, OriginFlag, "5s", false)
+
+	expected := "FLAG     VALUE  ORIGIN        RAW\n" +
+		"port     8080   programmatic  \n" +
+		"timeout  5s     flag          5s\n" +
+		"name            -             \n"
+
+	var buf bytes.Buffer
+	err := r.Print(&buf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, buf.String())
+	assert.Equal(t, expected, r.String())
+}
+
+func TestFormatValue(t *testing.T) {
+	u, _ := url.Parse("https://example.com")
+	i := ptr.Int(8080)
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{"Int", ptr.Int(8080), 8080},
+		{"IntPointer", &i, 8080},
+		{"NilPointer", new(*int), "<nil>"},
+		{"URL", u, "https://example.com"},
+		{"URLPointer", &u, "https://example.com"},
+		{"Slice", &[]string{"a", "b"}, []string{"a", "b"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.value).Elem()
+
+			assert.Equal(t, tc.expected, formatValue(v))
+		})
+	}
+}
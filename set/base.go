@@ -0,0 +1,196 @@
+package set
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Base sets the base for parsing integer values (between 2 and 36).
+// An optional prefix matching the base (0b, 0o, or 0x) and underscores are allowed in the values.
+// Without a base, the values with a base prefix (0b1010, 0o755, or 0x1F) are parsed in the base of the prefix,
+// underscores are allowed between the digits (1_000_000), and the other values are decimal.
+func Base(base int) Option {
+	return func(o *options) {
+		o.base = base
+	}
+}
+
+// IsBaseSupported determines whether or not a base can be used for a type.
+// The bases are supported for integer types (except time.Duration), and pointers to and slices of them.
+func IsBaseSupported(base int, t reflect.Type) bool {
+	if base < 2 || base > 36 {
+		return false
+	}
+
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	return isInteger(t) || isFileModeType(t)
+}
+
+var basePrefixes = map[int]string{
+	2:  "0b",
+	8:  "0o",
+	16: "0x",
+}
+
+// splitSign splits an integer value into its sign and the rest of it.
+func splitSign(val string) (string, string) {
+	if strings.HasPrefix(val, "-") || strings.HasPrefix(val, "+") {
+		return val[:1], val[1:]
+	}
+
+	return "", val
+}
+
+// hasBasePrefix determines whether or not an integer value has a base prefix (0b, 0o, or 0x).
+func hasBasePrefix(val string) bool {
+	_, s := splitSign(val)
+	return len(s) > 2 && s[0] == '0' && strings.ContainsRune("bBoOxX", rune(s[1]))
+}
+
+// intLiteral returns an integer value and the base for parsing it with the strconv package.
+func intLiteral(val string, base int) (string, int) {
+	if base == 0 {
+		if hasBasePrefix(val) {
+			return val, 0
+		}
+
+		// The leading zeros do not make a value octal unlike Go integer literals (01_000 is 1000)
+		if sign, s := splitSign(val); isDecimalWithUnderscores(s) {
+			return sign + strings.ReplaceAll(s, "_", ""), 10
+		}
+
+		return val, 10
+	}
+
+	sign, s := splitSign(val)
+	if prefix, ok := basePrefixes[base]; ok && len(s) > 2 && strings.EqualFold(s[:2], prefix) {
+		s = s[2:]
+	}
+
+	return sign + strings.ReplaceAll(s, "_", ""), base
+}
+
+// isDecimalWithUnderscores determines whether or not a value has underscores and only between the decimal digits.
+func isDecimalWithUnderscores(s string) bool {
+	if !strings.Contains(s, "_") || strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") || strings.Contains(s, "__") {
+		return false
+	}
+
+	return strings.Trim(s, "0123456789_") == ""
+}
+
+// parseInt parses a signed integer value using the base option.
+func parseInt(val string, bitSize int, opts ...Option) (int64, error) {
+	s, base := intLiteral(val, newOptions(opts...).base)
+	return strconv.ParseInt(s, base, bitSize)
+}
+
+// parseUint parses an unsigned integer value using the base option.
+func parseUint(val string, bitSize int, opts ...Option) (uint64, error) {
+	s, base := intLiteral(val, newOptions(opts...).base)
+	return strconv.ParseUint(s, base, bitSize)
+}
+
+// FormatBase formats an integer value or a pointer to an integer value in a base.
+// The prefix of the base (0b, 0o, or 0x) is added if the base has one.
+func FormatBase(v reflect.Value, base int) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		v = v.Elem()
+	}
+
+	var sign, digits string
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		digits = strconv.FormatUint(uint64(i), base)
+		if i < 0 {
+			sign, digits = "-", strconv.FormatUint(uint64(-i), base)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		digits = strconv.FormatUint(v.Uint(), base)
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+
+	return sign + basePrefixes[base] + digits
+}
+
+// isFileModeType determines whether or not a type is os.FileMode (io/fs.FileMode).
+func isFileModeType(t reflect.Type) bool {
+	return t == reflect.TypeOf(os.FileMode(0))
+}
+
+// parseFileMode parses a file mode.
+// Unless the value has a base prefix or the base option is set, the value is octal (755 or 0755).
+func parseFileMode(val string, opts ...Option) (os.FileMode, error) {
+	if newOptions(opts...).base == 0 && !hasBasePrefix(val) {
+		opts = append(opts, Base(8))
+	}
+
+	u, err := parseUint(val, 32, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return os.FileMode(u), nil
+}
+
+// FileMode sets an os.FileMode value.
+func FileMode(v reflect.Value, val string, opts ...Option) (bool, error) {
+	m, err := parseFileMode(val, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	if v.Interface() == m {
+		return false, nil
+	}
+
+	v.Set(reflect.ValueOf(m))
+	return true, nil
+}
+
+// FileModePtr sets an os.FileMode pointer.
+func FileModePtr(v reflect.Value, val string, opts ...Option) (bool, error) {
+	m, err := parseFileMode(val, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	if !v.IsZero() && v.Elem().Interface() == m {
+		return false, nil
+	}
+
+	v.Set(reflect.ValueOf(&m))
+	return true, nil
+}
+
+// FileModeSlice sets an os.FileMode slice.
+func FileModeSlice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	modes := []os.FileMode{}
+	for _, val := range vals {
+		m, err := parseFileMode(val, opts...)
+		if err != nil {
+			return false, err
+		}
+
+		modes = append(modes, m)
+	}
+
+	if reflect.DeepEqual(v.Interface(), modes) {
+		return false, nil
+	}
+
+	v.Set(reflect.ValueOf(modes))
+	return true, nil
+}
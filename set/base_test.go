@@ -0,0 +1,422 @@
+package set
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBaseSupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     int
+		value    interface{}
+		expected bool
+	}{
+		{"Int", 16, int(0), true},
+		{"Uint8Pointer", 2, new(uint8), true},
+		{"Int64Slice", 8, []int64{}, true},
+		{"FileMode", 10, os.FileMode(0), true},
+		{"Duration", 10, time.Duration(0), false},
+		{"Float64", 10, float64(0), false},
+		{"BaseTooSmall", 1, int(0), false},
+		{"BaseTooLarge", 37, int(0), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsBaseSupported(tc.base, reflect.TypeOf(tc.value)))
+		})
+	}
+}
+
+func TestIntLiteral(t *testing.T) {
+	tests := []struct {
+		name         string
+		val          string
+		base         int
+		expectedVal  string
+		expectedBase int
+	}{
+		{"Decimal", "1234", 0, "1234", 10},
+		{"LeadingZero", "0755", 0, "0755", 10},
+		{"Binary", "0b1010", 0, "0b1010", 0},
+		{"Octal", "0o755", 0, "0o755", 0},
+		{"Hex", "0x1F", 0, "0x1F", 0},
+		{"NegativeHex", "-0x1F", 0, "-0x1F", 0},
+		{"Underscores", "1_000_000", 0, "1000000", 10},
+		{"LeadingZeroUnderscores", "01_000", 0, "01000", 10},
+		{"NegativeUnderscores", "-0_7", 0, "-07", 10},
+		{"InvalidUnderscores", "1__000", 0, "1__000", 10},
+		{"HexUnderscores", "0xFF_FF", 0, "0xFF_FF", 0},
+		{"Base16", "1F", 16, "1F", 16},
+		{"Base16_Prefix", "0X1F", 16, "1F", 16},
+		{"Base16_NegativePrefix", "-0x1F", 16, "-1F", 16},
+		{"Base2_Underscores", "0b1111_0000", 2, "11110000", 2},
+		{"Base8_OtherPrefix", "0x1F", 8, "0x1F", 8},
+		{"Base36", "zz", 36, "zz", 36},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			val, base := intLiteral(tc.val, tc.base)
+
+			assert.Equal(t, tc.expectedVal, val)
+			assert.Equal(t, tc.expectedBase, base)
+		})
+	}
+}
+
+func TestFormatBase(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		base     int
+		expected string
+	}{
+		{"Hex", int(255), 16, "0xff"},
+		{"NegativeHex", int8(-16), 16, "-0x10"},
+		{"Octal", uint32(0755), 8, "0o755"},
+		{"Binary", uint8(10), 2, "0b1010"},
+		{"Base36", uint64(35), 36, "z"},
+		{"Pointer", func() *int { i := 31; return &i }(), 16, "0x1f"},
+		{"NilPointer", (*int)(nil), 16, "<nil>"},
+		{"NotInteger", "10", 16, "10"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatBase(reflect.ValueOf(tc.value), tc.base))
+		})
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               os.FileMode
+		val             string
+		opts            []Option
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  os.FileMode
+	}{
+		{
+			"Octal",
+			0, "755",
+			nil,
+			true, "",
+			0755,
+		},
+		{
+			"LeadingZero",
+			0, "0640",
+			nil,
+			true, "",
+			0640,
+		},
+		{
+			"Prefix",
+			0, "0o600",
+			nil,
+			true, "",
+			0600,
+		},
+		{
+			"Hex",
+			0, "0x1ff",
+			nil,
+			true, "",
+			0777,
+		},
+		{
+			"Base",
+			0, "493",
+			[]Option{Base(10)},
+			true, "",
+			0755,
+		},
+		{
+			"NoNewValue",
+			0755, "0755",
+			nil,
+			false, "",
+			0755,
+		},
+		{
+			"InvalidValue",
+			0644, "rwxr-xr-x",
+			nil,
+			false, `strconv.ParseUint: parsing "rwxr-xr-x": invalid syntax`,
+			0644,
+		},
+		{
+			"InvalidDigit",
+			0644, "789",
+			nil,
+			false, `strconv.ParseUint: parsing "789": invalid syntax`,
+			0644,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := FileMode(v, tc.val, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestFileModePtr(t *testing.T) {
+	m1, m2 := os.FileMode(0644), os.FileMode(0755)
+
+	tests := []struct {
+		name            string
+		s               *os.FileMode
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  *os.FileMode
+	}{
+		{
+			"Nil",
+			nil, "644",
+			true, "",
+			&m1,
+		},
+		{
+			"NewValue",
+			&m1, "0755",
+			true, "",
+			&m2,
+		},
+		{
+			"NoNewValue",
+			&m2, "755",
+			false, "",
+			&m2,
+		},
+		{
+			"InvalidValue",
+			&m1, "8",
+			false, `strconv.ParseUint: parsing "8": invalid syntax`,
+			&m1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := FileModePtr(v, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestFileModeSlice(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               []os.FileMode
+		vals            []string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  []os.FileMode
+	}{
+		{
+			"NewValue",
+			[]os.FileMode{}, []string{"644", "0o755"},
+			true, "",
+			[]os.FileMode{0644, 0755},
+		},
+		{
+			"NoNewValue",
+			[]os.FileMode{0644, 0755}, []string{"0644", "755"},
+			false, "",
+			[]os.FileMode{0644, 0755},
+		},
+		{
+			"InvalidValue",
+			[]os.FileMode{}, []string{"644", "999"},
+			false, `strconv.ParseUint: parsing "999": invalid syntax`,
+			[]os.FileMode{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := FileModeSlice(v, tc.vals)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestValueBase(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		sep             string
+		val             string
+		opts            []Option
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"Hex",
+			new(int),
+			"", "0x1F",
+			nil,
+			true, "",
+			func() *int { i := 31; return &i }(),
+		},
+		{
+			"Binary",
+			new(uint8),
+			"", "0b1010",
+			nil,
+			true, "",
+			func() *uint8 { u := uint8(10); return &u }(),
+		},
+		{
+			"Underscores",
+			new(int64),
+			"", "1_000_000",
+			nil,
+			true, "",
+			func() *int64 { i := int64(1000000); return &i }(),
+		},
+		{
+			"LeadingZero",
+			new(int),
+			"", "010",
+			nil,
+			true, "",
+			func() *int { i := 10; return &i }(),
+		},
+		{
+			"LeadingZeroUnderscores",
+			new(int),
+			"", "01_000",
+			nil,
+			true, "",
+			func() *int { i := 1000; return &i }(),
+		},
+		{
+			"TrailingUnderscore",
+			new(int),
+			"", "1000_",
+			nil,
+			false, `strconv.ParseInt: parsing "1000_": invalid syntax`,
+			new(int),
+		},
+		{
+			"Base16",
+			new(uint32),
+			"", "ff",
+			[]Option{Base(16)},
+			true, "",
+			func() *uint32 { u := uint32(255); return &u }(),
+		},
+		{
+			"Base16Pointer",
+			new(*int16),
+			"", "-0x7f",
+			[]Option{Base(16)},
+			true, "",
+			func() **int16 { i := int16(-127); p := &i; return &p }(),
+		},
+		{
+			"Base2Slice",
+			new([]uint),
+			",", "1010,0b11",
+			[]Option{Base(2)},
+			true, "",
+			&[]uint{10, 3},
+		},
+		{
+			"FileMode",
+			new(os.FileMode),
+			"", "0755",
+			nil,
+			true, "",
+			func() *os.FileMode { m := os.FileMode(0755); return &m }(),
+		},
+		{
+			"FileModePointer",
+			new(*os.FileMode),
+			"", "600",
+			nil,
+			true, "",
+			func() **os.FileMode { m := os.FileMode(0600); p := &m; return &p }(),
+		},
+		{
+			"FileModeSlice",
+			new([]os.FileMode),
+			",", "644,755",
+			nil,
+			true, "",
+			&[]os.FileMode{0644, 0755},
+		},
+		{
+			"InvalidPrefix",
+			new(int),
+			"", "0xZZ",
+			nil,
+			false, `strconv.ParseInt: parsing "0xZZ": invalid syntax`,
+			new(int),
+		},
+		{
+			"OutOfRange",
+			new(uint8),
+			"", "0x100",
+			nil,
+			false, `strconv.ParseUint: parsing "0x100": value out of range`,
+			new(uint8),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Value(v, tc.sep, tc.val, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
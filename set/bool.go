@@ -0,0 +1,86 @@
+package set
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BoolVocabulary is the words accepted as boolean values.
+type BoolVocabulary struct {
+	// True is the words for the true value.
+	True []string
+	// False is the words for the false value.
+	False []string
+	// CaseSensitive determines whether or not the words should match the values exactly.
+	CaseSensitive bool
+}
+
+var (
+	// DefaultBoolVocabulary is the default vocabulary used by the bool setters.
+	// It accepts the values accepted by strconv.ParseBool, yes/no, y/n, on/off, enable/disable, and enabled/disabled in any case.
+	DefaultBoolVocabulary = BoolVocabulary{
+		True:  []string{"1", "t", "true", "y", "yes", "on", "enable", "enabled"},
+		False: []string{"0", "f", "false", "n", "no", "off", "disable", "disabled"},
+	}
+
+	// StrictBoolVocabulary is the vocabulary of strconv.ParseBool.
+	StrictBoolVocabulary = BoolVocabulary{
+		True:          []string{"1", "t", "T", "TRUE", "true", "True"},
+		False:         []string{"0", "f", "F", "FALSE", "false", "False"},
+		CaseSensitive: true,
+	}
+)
+
+var vocabulary = struct {
+	sync.RWMutex
+	words map[string]bool
+	fold  bool
+}{}
+
+func init() {
+	SetBoolVocabulary(DefaultBoolVocabulary)
+}
+
+// SetBoolVocabulary sets the vocabulary used by all bool setters (Bool, BoolPtr, BoolSlice, and Value).
+// Use StrictBoolVocabulary for accepting only the values accepted by strconv.ParseBool.
+func SetBoolVocabulary(v BoolVocabulary) {
+	words := map[string]bool{}
+	for _, w := range v.False {
+		if !v.CaseSensitive {
+			w = strings.ToLower(w)
+		}
+		words[w] = false
+	}
+	for _, w := range v.True {
+		if !v.CaseSensitive {
+			w = strings.ToLower(w)
+		}
+		words[w] = true
+	}
+
+	vocabulary.Lock()
+	defer vocabulary.Unlock()
+
+	vocabulary.words = words
+	vocabulary.fold = !v.CaseSensitive
+}
+
+// ParseBool parses a boolean value using the vocabulary set by SetBoolVocabulary.
+// Similar to strconv.ParseBool, the error for an invalid value is a *strconv.NumError.
+func ParseBool(val string) (bool, error) {
+	vocabulary.RLock()
+	defer vocabulary.RUnlock()
+
+	w := val
+	if vocabulary.fold {
+		w = strings.ToLower(w)
+	}
+
+	b, ok := vocabulary.words[w]
+	if !ok {
+		return false, &strconv.NumError{Func: "ParseBool", Num: val, Err: strconv.ErrSyntax}
+	}
+
+	return b, nil
+}
@@ -0,0 +1,114 @@
+package set
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		name           string
+		vocabulary     BoolVocabulary
+		val            string
+		expectedError  string
+		expectedResult bool
+	}{
+		{"Default_True", DefaultBoolVocabulary, "true", "", true},
+		{"Default_One", DefaultBoolVocabulary, "1", "", true},
+		{"Default_Yes", DefaultBoolVocabulary, "yes", "", true},
+		{"Default_On", DefaultBoolVocabulary, "ON", "", true},
+		{"Default_Enabled", DefaultBoolVocabulary, "Enabled", "", true},
+		{"Default_No", DefaultBoolVocabulary, "No", "", false},
+		{"Default_Off", DefaultBoolVocabulary, "off", "", false},
+		{"Default_Disabled", DefaultBoolVocabulary, "DISABLED", "", false},
+		{"Default_Invalid", DefaultBoolVocabulary, "maybe", `strconv.ParseBool: parsing "maybe": invalid syntax`, false},
+		{"Strict_True", StrictBoolVocabulary, "TRUE", "", true},
+		{"Strict_False", StrictBoolVocabulary, "f", "", false},
+		{"Strict_Yes", StrictBoolVocabulary, "yes", `strconv.ParseBool: parsing "yes": invalid syntax`, false},
+		{"Strict_MixedCase", StrictBoolVocabulary, "tRUE", `strconv.ParseBool: parsing "tRUE": invalid syntax`, false},
+		{
+			"Custom_True",
+			BoolVocabulary{True: []string{"ja"}, False: []string{"nein"}},
+			"JA", "", true,
+		},
+		{
+			"Custom_CaseSensitive",
+			BoolVocabulary{True: []string{"ja"}, False: []string{"nein"}, CaseSensitive: true},
+			"Nein", `strconv.ParseBool: parsing "Nein": invalid syntax`, false,
+		},
+	}
+
+	defer SetBoolVocabulary(DefaultBoolVocabulary)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			SetBoolVocabulary(tc.vocabulary)
+			b, err := ParseBool(tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, b)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestValueBoolVocabulary(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"Bool",
+			new(bool),
+			"on",
+			true, "",
+			func() *bool { b := true; return &b }(),
+		},
+		{
+			"BoolPointer",
+			new(*bool),
+			"No",
+			true, "",
+			func() **bool { b := false; p := &b; return &p }(),
+		},
+		{
+			"BoolSlice",
+			new([]bool),
+			"yes,off,Enabled",
+			true, "",
+			&[]bool{true, false, true},
+		},
+		{
+			"Invalid",
+			new(bool),
+			"sure",
+			false, `strconv.ParseBool: parsing "sure": invalid syntax`,
+			new(bool),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Value(v, ",", tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
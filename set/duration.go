@@ -0,0 +1,196 @@
+package set
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// parseDuration parses a duration value.
+// In addition to the syntax of time.ParseDuration, the values can have the d (day) and w (week) units (7d, 2w, or 1d12h)
+// and can be ISO 8601 durations (P30D, PT1H30M, or P1DT12H).
+// A day is always 24 hours.
+func parseDuration(val string) (time.Duration, error) {
+	sign, s := splitSign(val)
+
+	if strings.HasPrefix(s, "P") {
+		d, ok := parseISODuration(s)
+		if !ok {
+			return 0, fmt.Errorf("time: invalid duration %q", val)
+		}
+
+		if sign == "-" {
+			d = -d
+		}
+
+		return d, nil
+	}
+
+	if !strings.ContainsAny(s, "dw") {
+		return time.ParseDuration(val)
+	}
+
+	var total float64
+	var rest string
+
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, fmt.Errorf("time: invalid duration %q", val)
+		}
+
+		j := strings.IndexAny(s[i:], "0123456789.")
+		if j < 0 {
+			j = len(s) - i
+		}
+
+		num, unit := s[:i], s[i:i+j]
+		s = s[i+j:]
+
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, fmt.Errorf("time: invalid duration %q", val)
+			}
+
+			if unit == "d" {
+				total += f * float64(day)
+			} else {
+				total += f * float64(week)
+			}
+		default:
+			rest += num + unit
+		}
+	}
+
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("time: invalid duration %q", val)
+		}
+
+		total += float64(d)
+	}
+
+	if total >= math.MaxInt64 {
+		return 0, fmt.Errorf("time: invalid duration %q", val)
+	}
+
+	d := time.Duration(math.Round(total))
+	if sign == "-" {
+		d = -d
+	}
+
+	return d, nil
+}
+
+// parseISODuration parses an ISO 8601 duration without any sign (PnW, PnD, PTnHnMnS, or PnDTnHnMnS).
+// Years and months are not supported since they do not have a fixed length.
+// Only the last component can have a fraction.
+func parseISODuration(val string) (time.Duration, bool) {
+	s := strings.TrimPrefix(val, "P")
+	if s == "" || strings.HasSuffix(s, "T") {
+		return 0, false
+	}
+
+	units := map[byte]time.Duration{
+		'W': week,
+		'D': day,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+
+	var total float64
+	var fraction bool
+
+	// The designators that can still appear in order
+	designators := "WDT"
+
+	for s != "" {
+		if s[0] == 'T' {
+			k := strings.IndexByte(designators, 'T')
+			if k < 0 {
+				return 0, false
+			}
+			designators, s = "HMS", s[1:]
+			continue
+		}
+
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != ',' })
+		if i <= 0 || fraction {
+			return 0, false
+		}
+
+		num, designator := strings.Replace(s[:i], ",", ".", 1), s[i]
+		s = s[i+1:]
+
+		k := strings.IndexByte(designators, designator)
+		if k < 0 || designator == 'T' {
+			return 0, false
+		}
+		designators = designators[k+1:]
+
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		fraction = strings.Contains(num, ".")
+		total += f * float64(units[designator])
+	}
+
+	if total >= math.MaxInt64 {
+		return 0, false
+	}
+
+	return time.Duration(math.Round(total)), true
+}
+
+// FormatDuration formats a duration in the most readable units.
+// Whole weeks are formatted as weeks (2w), whole days as days (30d), and the rest similar to time.Duration
+// with the trailing zero units omitted (1d12h or 1h30m).
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+
+	var sign string
+	if d < 0 {
+		// The minimum duration cannot be negated
+		if d == math.MinInt64 {
+			return d.String()
+		}
+		sign, d = "-", -d
+	}
+
+	if d%week == 0 {
+		return sign + strconv.FormatInt(int64(d/week), 10) + "w"
+	}
+
+	var s string
+	if days := d / day; days > 0 {
+		s, d = strconv.FormatInt(int64(days), 10)+"d", d%day
+	}
+
+	if d > 0 {
+		rest := d.String()
+		if strings.HasSuffix(rest, "m0s") {
+			rest = strings.TrimSuffix(rest, "0s")
+		}
+		if strings.HasSuffix(rest, "h0m") {
+			rest = strings.TrimSuffix(rest, "0m")
+		}
+		s += rest
+	}
+
+	return sign + s
+}
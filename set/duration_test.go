@@ -0,0 +1,138 @@
+package set
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name           string
+		val            string
+		expectedError  string
+		expectedResult time.Duration
+	}{
+		{"Go", "1h30m", "", 90 * time.Minute},
+		{"Go_Negative", "-1.5s", "", -1500 * time.Millisecond},
+		{"Go_Invalid", "1x", `time: unknown unit "x" in duration "1x"`, 0},
+		{"Days", "7d", "", 7 * 24 * time.Hour},
+		{"Weeks", "2w", "", 14 * 24 * time.Hour},
+		{"FractionalDays", "1.5d", "", 36 * time.Hour},
+		{"DaysAndHours", "1d12h", "", 36 * time.Hour},
+		{"WeeksDaysAndMinutes", "1w2d30m", "", 9*24*time.Hour + 30*time.Minute},
+		{"NegativeDays", "-3d", "", -3 * 24 * time.Hour},
+		{"Days_InvalidRest", "1d2x", `time: invalid duration "1d2x"`, 0},
+		{"Days_MissingNumber", "d", `time: invalid duration "d"`, 0},
+		{"ISO_Days", "P30D", "", 30 * 24 * time.Hour},
+		{"ISO_Weeks", "P2W", "", 14 * 24 * time.Hour},
+		{"ISO_Time", "PT1H30M", "", 90 * time.Minute},
+		{"ISO_DaysAndTime", "P1DT12H", "", 36 * time.Hour},
+		{"ISO_Seconds", "PT0.5S", "", 500 * time.Millisecond},
+		{"ISO_Comma", "PT1,5M", "", 90 * time.Second},
+		{"ISO_Negative", "-PT15M", "", -15 * time.Minute},
+		{"ISO_Months", "P1M", `time: invalid duration "P1M"`, 0},
+		{"ISO_Years", "P1Y", `time: invalid duration "P1Y"`, 0},
+		{"ISO_Empty", "P", `time: invalid duration "P"`, 0},
+		{"ISO_EmptyTime", "P1DT", `time: invalid duration "P1DT"`, 0},
+		{"ISO_Order", "PT30M1H", `time: invalid duration "PT30M1H"`, 0},
+		{"ISO_FractionNotLast", "PT1.5H30M", `time: invalid duration "PT1.5H30M"`, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := parseDuration(tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, d)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		d        time.Duration
+		expected string
+	}{
+		{"Zero", 0, "0s"},
+		{"Milliseconds", 500 * time.Millisecond, "500ms"},
+		{"Seconds", 90 * time.Second, "1m30s"},
+		{"Minutes", 30 * time.Minute, "30m"},
+		{"HoursAndMinutes", 90 * time.Minute, "1h30m"},
+		{"Hours", 12 * time.Hour, "12h"},
+		{"HoursAndSeconds", time.Hour + time.Second, "1h0m1s"},
+		{"Days", 30 * 24 * time.Hour, "30d"},
+		{"DaysAndHours", 36 * time.Hour, "1d12h"},
+		{"Weeks", 14 * 24 * time.Hour, "2w"},
+		{"Negative", -7 * 24 * time.Hour, "-1w"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatDuration(tc.d))
+		})
+	}
+}
+
+func TestValueDuration(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"Duration",
+			new(time.Duration),
+			"7d",
+			true, "",
+			func() *time.Duration { d := 7 * 24 * time.Hour; return &d }(),
+		},
+		{
+			"DurationPointer",
+			new(*time.Duration),
+			"P30D",
+			true, "",
+			func() **time.Duration { d := 30 * 24 * time.Hour; p := &d; return &p }(),
+		},
+		{
+			"DurationSlice",
+			new([]time.Duration),
+			"1h,2w,PT15M",
+			true, "",
+			&[]time.Duration{time.Hour, 14 * 24 * time.Hour, 15 * time.Minute},
+		},
+		{
+			"Invalid",
+			new(time.Duration),
+			"P1Y",
+			false, `time: invalid duration "P1Y"`,
+			new(time.Duration),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Value(v, ",", tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package set
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Choice is a symbolic name for a value of an enum.
+type Choice struct {
+	Name  string
+	Value string
+}
+
+// ParseChoices parses a list of choices separated by commas.
+// Each choice is either in the name=value form (debug=0) or only a name if the name is also the value (json).
+// The names are case-insensitive and should be unique.
+func ParseChoices(s string) ([]Choice, error) {
+	choices := []Choice{}
+	names := map[string]bool{}
+
+	for _, c := range strings.Split(s, ",") {
+		name, value := c, c
+		if i := strings.Index(c, "="); i >= 0 {
+			name, value = c[:i], c[i+1:]
+		}
+
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" {
+			return nil, fmt.Errorf("invalid choice: %q", c)
+		}
+
+		key := strings.ToLower(name)
+		if names[key] {
+			return nil, fmt.Errorf("duplicate choice: %s", name)
+		}
+		names[key] = true
+
+		choices = append(choices, Choice{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	return choices, nil
+}
+
+// ChoiceNames returns the names of a list of choices separated by commas.
+func ChoiceNames(choices []Choice) string {
+	names := make([]string, len(choices))
+	for i, c := range choices {
+		names[i] = c.Name
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Enum sets the choices for parsing values by their names.
+// The names are case-insensitive and the values that are not any of the names are rejected.
+// For slices, the choices are used for every element. For maps, the choices are only used for the elements, but not the keys.
+func Enum(choices ...Choice) Option {
+	return func(o *options) {
+		o.enum = choices
+	}
+}
+
+// RegisterEnum registers the choices for a type, so the values of the type, pointers to the type, and slices of them
+// are always set by the names of the choices (see Enum).
+// If the type is a pointer type, the choices are registered for the type it points to.
+// Registering no choices removes the choices registered for the type.
+func RegisterEnum(t reflect.Type, choices ...Choice) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if len(choices) == 0 {
+		delete(registry.enums, t)
+	} else {
+		registry.enums[t] = append([]Choice{}, choices...)
+	}
+}
+
+// LookupEnum returns the choices registered for a type.
+func LookupEnum(t reflect.Type) ([]Choice, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	choices, ok := registry.enums[t]
+	return choices, ok
+}
+
+// enumChoices returns the choices for a type either from the options or the registered ones.
+func enumChoices(t reflect.Type, opts ...Option) []Choice {
+	if o := newOptions(opts...); len(o.enum) > 0 {
+		return o.enum
+	}
+
+	choices, _ := LookupEnum(t)
+	return choices
+}
+
+// resolveEnum replaces the name of a choice with its value.
+// Without any choices, the value is returned as is.
+func resolveEnum(val string, choices []Choice) (string, error) {
+	if len(choices) == 0 {
+		return val, nil
+	}
+
+	for _, c := range choices {
+		if strings.EqualFold(val, c.Name) {
+			return c.Value, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid value %q: must be one of %s", val, ChoiceNames(choices))
+}
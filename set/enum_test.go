@@ -0,0 +1,259 @@
+package set
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	level       int
+	uint16Level uint16
+	format      string
+	toggle      bool
+	ratio       float64
+	ratio32     float32
+)
+
+var levels = []Choice{
+	{"debug", "0"},
+	{"info", "1"},
+	{"warn", "2"},
+	{"error", "3"},
+}
+
+func TestParseChoices(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               string
+		expectedError   string
+		expectedChoices []Choice
+	}{
+		{"NameValue", "debug=0,info=1,warn=2,error=3", "", levels},
+		{"NameOnly", "json,text", "", []Choice{{"json", "json"}, {"text", "text"}}},
+		{"Spaces", "low = 1, high = 9", "", []Choice{{"low", "1"}, {"high", "9"}}},
+		{"EmptyName", "debug=0,=1", `invalid choice: "=1"`, nil},
+		{"Empty", "", `invalid choice: ""`, nil},
+		{"Duplicate", "debug=0,DEBUG=1", "duplicate choice: DEBUG", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			choices, err := ParseChoices(tc.s)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedChoices, choices)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestChoiceNames(t *testing.T) {
+	assert.Equal(t, "debug, info, warn, error", ChoiceNames(levels))
+}
+
+func TestRegisterEnum(t *testing.T) {
+	RegisterEnum(reflect.TypeOf(new(level)), levels...)
+
+	choices, ok := LookupEnum(reflect.TypeOf(level(0)))
+	assert.True(t, ok)
+	assert.Equal(t, levels, choices)
+
+	var l level
+	updated, err := Value(reflect.ValueOf(&l).Elem(), "", "Warn")
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, level(2), l)
+
+	var ls []level
+	updated, err = Value(reflect.ValueOf(&ls).Elem(), ",", "debug,error")
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, []level{0, 3}, ls)
+
+	_, err = Value(reflect.ValueOf(&l).Elem(), "", "2")
+	assert.EqualError(t, err, `invalid value "2": must be one of debug, info, warn, error`)
+
+	RegisterEnum(reflect.TypeOf(level(0)))
+
+	_, ok = LookupEnum(reflect.TypeOf(level(0)))
+	assert.False(t, ok)
+}
+
+func TestValueEnum(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		sep             string
+		val             string
+		opts            []Option
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"Int",
+			new(int),
+			"", "warn",
+			[]Option{Enum(levels...)},
+			true, "",
+			func() *int { i := 2; return &i }(),
+		},
+		{
+			"CaseInsensitive",
+			new(uint8),
+			"", "ERROR",
+			[]Option{Enum(levels...)},
+			true, "",
+			func() *uint8 { u := uint8(3); return &u }(),
+		},
+		{
+			"String",
+			new(string),
+			"", "Text",
+			[]Option{Enum(Choice{"json", "json"}, Choice{"text", "text"})},
+			true, "",
+			func() *string { s := "text"; return &s }(),
+		},
+		{
+			"Pointer",
+			new(*int),
+			"", "info",
+			[]Option{Enum(levels...)},
+			true, "",
+			func() **int { i := 1; p := &i; return &p }(),
+		},
+		{
+			"NamedPointer",
+			new(*level),
+			"", "Info",
+			[]Option{Enum(levels...)},
+			true, "",
+			func() **level { l := level(1); p := &l; return &p }(),
+		},
+		{
+			"NamedSliceWithoutEnum",
+			new([]level),
+			",", "0,3",
+			nil,
+			true, "",
+			&[]level{0, 3},
+		},
+		{
+			"NamedUintPointerWithoutEnum",
+			new(*uint16Level),
+			"", "0x10",
+			nil,
+			true, "",
+			func() **uint16Level { u := uint16Level(16); p := &u; return &p }(),
+		},
+		{
+			"NamedStringPointer",
+			new(*format),
+			"", "JSON",
+			[]Option{Enum(Choice{"json", "json"}, Choice{"text", "text"})},
+			true, "",
+			func() **format { f := format("json"); p := &f; return &p }(),
+		},
+		{
+			"NamedStringSlice",
+			new([]format),
+			",", "text,json",
+			[]Option{Enum(Choice{"json", "json"}, Choice{"text", "text"})},
+			true, "",
+			&[]format{"text", "json"},
+		},
+		{
+			"NamedBoolPointerWithoutEnum",
+			new(*toggle),
+			"", "on",
+			nil,
+			true, "",
+			func() **toggle { b := toggle(true); p := &b; return &p }(),
+		},
+		{
+			"NamedFloatPointerWithoutEnum",
+			new(*ratio),
+			"", "0.25",
+			nil,
+			true, "",
+			func() **ratio { r := ratio(0.25); p := &r; return &p }(),
+		},
+		{
+			"NamedFloat32SliceWithoutEnum",
+			new([]ratio32),
+			",", "0.5,1.5",
+			nil,
+			true, "",
+			&[]ratio32{0.5, 1.5},
+		},
+		{
+			"Slice",
+			new([]int),
+			",", "debug,Warn",
+			[]Option{Enum(levels...)},
+			true, "",
+			&[]int{0, 2},
+		},
+		{
+			"MapElements",
+			new(map[string]int),
+			",", "db=debug,api=error",
+			[]Option{Enum(levels...)},
+			true, "",
+			&map[string]int{"db": 0, "api": 3},
+		},
+		{
+			"Hex",
+			new(int),
+			"", "high",
+			[]Option{Enum(Choice{"low", "0x01"}, Choice{"high", "0xff"})},
+			true, "",
+			func() *int { i := 255; return &i }(),
+		},
+		{
+			"Unknown",
+			new(int),
+			"", "trace",
+			[]Option{Enum(levels...)},
+			false, `invalid value "trace": must be one of debug, info, warn, error`,
+			new(int),
+		},
+		{
+			"UnknownInSlice",
+			new([]int),
+			",", "debug,verbose",
+			[]Option{Enum(levels...)},
+			false, `invalid value "verbose": must be one of debug, info, warn, error`,
+			new([]int),
+		},
+		{
+			"InvalidChoiceValue",
+			new(int),
+			"", "bad",
+			[]Option{Enum(Choice{"bad", "one"})},
+			false, `strconv.ParseInt: parsing "one": invalid syntax`,
+			new(int),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Value(v, tc.sep, tc.val, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
@@ -1,15 +1,376 @@
 package set
 
 import (
+	"encoding"
+	"flag"
 	"fmt"
+	"net"
 	"net/url"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	defaultKVSep  = "="
+	defaultLayout = time.RFC3339
+)
+
+type options struct {
+	kvSep    string
+	layout   string
+	location *time.Location
+	unit     string
+	base     int
+	enum     []Choice
+}
+
+// Option configures how a string value is parsed.
+type Option func(*options)
+
+// KeyValueSeparator sets the separator between the keys and the values of map entries.
+// The default separator is "=".
+func KeyValueSeparator(sep string) Option {
+	return func(o *options) {
+		if sep != "" {
+			o.kvSep = sep
+		}
+	}
+}
+
+// Layout sets the layout for parsing time values (see time.Parse).
+// The default layout is RFC 3339.
+func Layout(layout string) Option {
+	return func(o *options) {
+		if layout != "" {
+			o.layout = layout
+		}
+	}
+}
+
+// Location sets the location for parsing time values without time zone information and for Unix times.
+// The default location is UTC.
+func Location(loc *time.Location) Option {
+	return func(o *options) {
+		if loc != nil {
+			o.location = loc
+		}
+	}
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		kvSep:    defaultKVSep,
+		layout:   defaultLayout,
+		location: time.UTC,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+)
+
+// isBuiltinStruct determines whether or not a struct type is one of the struct types supported out of the box.
+// These types are always parsed the same way even if they implement any of the custom interfaces.
+func isBuiltinStruct(t reflect.Type) bool {
+	return (t.PkgPath() == "net/url" && t.Name() == "URL") ||
+		(t.PkgPath() == "regexp" && t.Name() == "Regexp") ||
+		(t.PkgPath() == "net" && t.Name() == "IPNet") ||
+		(t.PkgPath() == "net" && t.Name() == "TCPAddr") ||
+		isTimeType(t)
+}
+
+// isHardwareAddrType determines whether or not a type is net.HardwareAddr.
+func isHardwareAddrType(t reflect.Type) bool {
+	return t.PkgPath() == "net" && t.Name() == "HardwareAddr"
+}
+
+// parseCIDR parses a network in CIDR notation (192.168.0.0/16 or 2001:db8::/32).
+func parseCIDR(val string) (*net.IPNet, error) {
+	_, n, err := net.ParseCIDR(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// parseTCPAddr parses a TCP address in the host:port form, where the host is either empty or a literal IP address.
+// Host names are not resolved, so parsing never makes any DNS queries.
+func parseTCPAddr(val string) (*net.TCPAddr, error) {
+	host, port, err := net.SplitHostPort(val)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port: %s", port)
+	}
+
+	addr := &net.TCPAddr{
+		Port: int(p),
+	}
+
+	if host != "" {
+		if i := strings.LastIndex(host, "%"); i >= 0 {
+			host, addr.Zone = host[:i], host[i+1:]
+		}
+
+		if addr.IP = net.ParseIP(host); addr.IP == nil {
+			return nil, fmt.Errorf("invalid IP address: %s", host)
+		}
+	}
+
+	return addr, nil
+}
+
+// isTimeType determines whether or not a type is time.Time.
+func isTimeType(t reflect.Type) bool {
+	return t.PkgPath() == "time" && t.Name() == "Time"
+}
+
+// parseTime parses a time value using the layout and the location options.
+// If the value cannot be parsed using the layout, a value consisting of an optional sign, digits,
+// and optionally a fraction is parsed as a Unix time in seconds.
+// The layout comes first, so the layouts with only digits (20060102 or 2006) are not mistaken for Unix times.
+func parseTime(val string, opts ...Option) (time.Time, error) {
+	o := newOptions(opts...)
+
+	t, err := time.ParseInLocation(o.layout, val, o.location)
+	if err == nil {
+		return t, nil
+	}
+
+	if sec, nsec, ok := parseUnix(val); ok {
+		return time.Unix(sec, nsec).In(o.location), nil
+	}
+
+	return time.Time{}, err
+}
+
+// parseUnix parses a Unix time in seconds with an optional fraction (up to nanoseconds).
+func parseUnix(val string) (int64, int64, bool) {
+	secs, frac := val, ""
+	if i := strings.Index(val, "."); i >= 0 {
+		secs, frac = val[:i], val[i+1:]
+		if frac == "" || len(frac) > 9 {
+			return 0, 0, false
+		}
+	}
+
+	digits := strings.TrimPrefix(secs, "-")
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" || strings.TrimLeft(frac, "0123456789") != "" {
+		return 0, 0, false
+	}
+
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	var nsec int64
+	if frac != "" {
+		nsec, _ = strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if strings.HasPrefix(secs, "-") {
+			nsec = -nsec
+		}
+	}
+
+	return sec, nsec, true
+}
+
+// setFloatPtr sets a float pointer to a new pointer.
+// The new pointer has the type of the field, so the pointers to the named float types can be set too.
+func setFloatPtr(v reflect.Value, f float64) {
+	p := reflect.New(v.Type().Elem())
+	p.Elem().SetFloat(f)
+	v.Set(p)
+}
+
+// convertSlice converts a slice to the type of the field, so the slices of the named types can be set too.
+func convertSlice(s reflect.Value, t reflect.Type) reflect.Value {
+	if s.Type() == t {
+		return s
+	}
+
+	c := reflect.MakeSlice(t, s.Len(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		c.Index(i).Set(s.Index(i).Convert(t.Elem()))
+	}
+
+	return c
+}
+
+// setIntPtr sets a signed integer pointer to a new pointer.
+// The new pointer has the type of the field, so the pointers to the named integer types can be set too.
+func setIntPtr(v reflect.Value, i int64) {
+	p := reflect.New(v.Type().Elem())
+	p.Elem().SetInt(i)
+	v.Set(p)
+}
+
+// setUintPtr sets an unsigned integer pointer to a new pointer.
+// The new pointer has the type of the field, so the pointers to the named integer types can be set too.
+func setUintPtr(v reflect.Value, u uint64) {
+	p := reflect.New(v.Type().Elem())
+	p.Elem().SetUint(u)
+	v.Set(p)
+}
+
+// intSlice sets a signed integer slice.
+// The new slice has the type of the field, so the slices of the named integer types can be set too.
+func intSlice(v reflect.Value, vals []string, bitSize int, opts ...Option) (bool, error) {
+	s := reflect.MakeSlice(v.Type(), 0, len(vals))
+	for _, val := range vals {
+		i, err := parseInt(val, bitSize, opts...)
+		if err != nil {
+			return false, err
+		}
+
+		e := reflect.New(v.Type().Elem()).Elem()
+		e.SetInt(i)
+		s = reflect.Append(s, e)
+	}
+
+	if reflect.DeepEqual(v.Interface(), s.Interface()) {
+		return false, nil
+	}
+
+	v.Set(s)
+	return true, nil
+}
+
+// uintSlice sets an unsigned integer slice.
+// The new slice has the type of the field, so the slices of the named integer types can be set too.
+func uintSlice(v reflect.Value, vals []string, bitSize int, opts ...Option) (bool, error) {
+	s := reflect.MakeSlice(v.Type(), 0, len(vals))
+	for _, val := range vals {
+		u, err := parseUint(val, bitSize, opts...)
+		if err != nil {
+			return false, err
+		}
+
+		e := reflect.New(v.Type().Elem()).Elem()
+		e.SetUint(u)
+		s = reflect.Append(s, e)
+	}
+
+	if reflect.DeepEqual(v.Interface(), s.Interface()) {
+		return false, nil
+	}
+
+	v.Set(s)
+	return true, nil
+}
+
+// ParseFunc parses a string value into a value of a registered type.
+// The returned value should be of the registered type or a pointer to it.
+type ParseFunc func(string) (interface{}, error)
+
+var registry = struct {
+	sync.RWMutex
+	parsers map[reflect.Type]ParseFunc
+	enums   map[reflect.Type][]Choice
+}{
+	parsers: map[reflect.Type]ParseFunc{},
+	enums:   map[reflect.Type][]Choice{},
+}
+
+// Register registers a parser for a type, so the values of the type, pointers to the type, and slices of them can be set.
+// This is useful for the types that cannot implement encoding.TextUnmarshaler or flag.Value.
+// The registered parsers take precedence over the custom interfaces and the built-in kinds.
+// If the type is a pointer type, the parser is registered for the type it points to.
+// Registering a nil parser removes the parser registered for the type.
+func Register(t reflect.Type, parse ParseFunc) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if parse == nil {
+		delete(registry.parsers, t)
+	} else {
+		registry.parsers[t] = parse
+	}
+}
+
+// lookupParser returns the parser registered for a type.
+func lookupParser(t reflect.Type) (ParseFunc, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	parse, ok := registry.parsers[t]
+	return parse, ok
+}
+
+// IsCustomType determines whether or not a non-pointer type is a custom type.
+// A custom type is either a type with a registered parser (see Register)
+// or a type that its pointer type implements either the encoding.TextUnmarshaler or the flag.Value interface.
+func IsCustomType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		return false
+	}
+
+	if _, ok := lookupParser(t); ok {
+		return true
+	}
+
+	if isBuiltinStruct(t) {
+		return false
+	}
+
+	pt := reflect.PtrTo(t)
+	return pt.Implements(textUnmarshalerType) || pt.Implements(flagValueType)
+}
+
+// unmarshal parses a string value into the value pointed to by a pointer of a custom type.
+// The registered parsers take precedence over the encoding.TextUnmarshaler interface
+// and the encoding.TextUnmarshaler interface takes precedence over the flag.Value interface.
+func unmarshal(ptr reflect.Value, val string) error {
+	t := ptr.Type().Elem()
+
+	if parse, ok := lookupParser(t); ok {
+		i, err := parse(val)
+		if err != nil {
+			return err
+		}
+
+		v := reflect.ValueOf(i)
+		switch {
+		case v.IsValid() && v.Type() == t:
+			ptr.Elem().Set(v)
+		case v.IsValid() && v.Type() == ptr.Type() && !v.IsNil():
+			ptr.Elem().Set(v.Elem())
+		default:
+			return fmt.Errorf("invalid parsed value for type %s: %T", t, i)
+		}
+
+		return nil
+	}
+
+	switch u := ptr.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return u.UnmarshalText([]byte(val))
+	case flag.Value:
+		return u.Set(val)
+	}
+
+	return fmt.Errorf("unsupported type: %s", t)
+}
+
 // String sets a string value.
 func String(v reflect.Value, val string) (bool, error) {
 	if v.String() == val {
@@ -22,7 +383,7 @@ func String(v reflect.Value, val string) (bool, error) {
 
 // Bool sets a bool value.
 func Bool(v reflect.Value, val string) (bool, error) {
-	b, err := strconv.ParseBool(val)
+	b, err := ParseBool(val)
 	if err != nil {
 		return false, err
 	}
@@ -66,9 +427,9 @@ func Float64(v reflect.Value, val string) (bool, error) {
 }
 
 // Int sets an int value.
-func Int(v reflect.Value, val string) (bool, error) {
+func Int(v reflect.Value, val string, opts ...Option) (bool, error) {
 	// int size and range are platform-dependent
-	i, err := strconv.ParseInt(val, 10, 64)
+	i, err := parseInt(val, 64, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -82,8 +443,8 @@ func Int(v reflect.Value, val string) (bool, error) {
 }
 
 // Int8 sets an int8 value.
-func Int8(v reflect.Value, val string) (bool, error) {
-	i, err := strconv.ParseInt(val, 10, 8)
+func Int8(v reflect.Value, val string, opts ...Option) (bool, error) {
+	i, err := parseInt(val, 8, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -97,8 +458,8 @@ func Int8(v reflect.Value, val string) (bool, error) {
 }
 
 // Int16 sets an int16 value.
-func Int16(v reflect.Value, val string) (bool, error) {
-	i, err := strconv.ParseInt(val, 10, 16)
+func Int16(v reflect.Value, val string, opts ...Option) (bool, error) {
+	i, err := parseInt(val, 16, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -112,8 +473,8 @@ func Int16(v reflect.Value, val string) (bool, error) {
 }
 
 // Int32 sets an int32 value.
-func Int32(v reflect.Value, val string) (bool, error) {
-	i, err := strconv.ParseInt(val, 10, 32)
+func Int32(v reflect.Value, val string, opts ...Option) (bool, error) {
+	i, err := parseInt(val, 32, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -127,9 +488,9 @@ func Int32(v reflect.Value, val string) (bool, error) {
 }
 
 // Int64 sets an int64 value.
-func Int64(v reflect.Value, val string) (bool, error) {
+func Int64(v reflect.Value, val string, opts ...Option) (bool, error) {
 	if t := v.Type(); t.PkgPath() == "time" && t.Name() == "Duration" {
-		d, err := time.ParseDuration(val)
+		d, err := parseDuration(val)
 		if err != nil {
 			return false, err
 		}
@@ -142,7 +503,7 @@ func Int64(v reflect.Value, val string) (bool, error) {
 		return true, nil
 	}
 
-	i, err := strconv.ParseInt(val, 10, 64)
+	i, err := parseInt(val, 64, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -156,9 +517,9 @@ func Int64(v reflect.Value, val string) (bool, error) {
 }
 
 // Uint sets an uint value.
-func Uint(v reflect.Value, val string) (bool, error) {
+func Uint(v reflect.Value, val string, opts ...Option) (bool, error) {
 	// uint size and range are platform-dependent
-	u, err := strconv.ParseUint(val, 10, 64)
+	u, err := parseUint(val, 64, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -172,8 +533,8 @@ func Uint(v reflect.Value, val string) (bool, error) {
 }
 
 // Uint8 sets an uint8 value.
-func Uint8(v reflect.Value, val string) (bool, error) {
-	u, err := strconv.ParseUint(val, 10, 8)
+func Uint8(v reflect.Value, val string, opts ...Option) (bool, error) {
+	u, err := parseUint(val, 8, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -187,8 +548,8 @@ func Uint8(v reflect.Value, val string) (bool, error) {
 }
 
 // Uint16 sets an uint16 value.
-func Uint16(v reflect.Value, val string) (bool, error) {
-	u, err := strconv.ParseUint(val, 10, 16)
+func Uint16(v reflect.Value, val string, opts ...Option) (bool, error) {
+	u, err := parseUint(val, 16, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -202,8 +563,8 @@ func Uint16(v reflect.Value, val string) (bool, error) {
 }
 
 // Uint32 sets an uint32 value.
-func Uint32(v reflect.Value, val string) (bool, error) {
-	u, err := strconv.ParseUint(val, 10, 32)
+func Uint32(v reflect.Value, val string, opts ...Option) (bool, error) {
+	u, err := parseUint(val, 32, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -217,8 +578,8 @@ func Uint32(v reflect.Value, val string) (bool, error) {
 }
 
 // Uint64 sets an uint64 value.
-func Uint64(v reflect.Value, val string) (bool, error) {
-	u, err := strconv.ParseUint(val, 10, 64)
+func Uint64(v reflect.Value, val string, opts ...Option) (bool, error) {
+	u, err := parseUint(val, 64, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -263,6 +624,34 @@ func Struct(v reflect.Value, val string) (bool, error) {
 		// r is a pointer
 		v.Set(reflect.ValueOf(r).Elem())
 		return true, nil
+	} else if t.PkgPath() == "net" && t.Name() == "IPNet" {
+		n, err := parseCIDR(val)
+		if err != nil {
+			return false, err
+		}
+
+		// n is a pointer
+		if reflect.DeepEqual(v.Interface(), *n) {
+			return false, nil
+		}
+
+		// n is a pointer
+		v.Set(reflect.ValueOf(n).Elem())
+		return true, nil
+	} else if t.PkgPath() == "net" && t.Name() == "TCPAddr" {
+		a, err := parseTCPAddr(val)
+		if err != nil {
+			return false, err
+		}
+
+		// a is a pointer
+		if reflect.DeepEqual(v.Interface(), *a) {
+			return false, nil
+		}
+
+		// a is a pointer
+		v.Set(reflect.ValueOf(a).Elem())
+		return true, nil
 	}
 
 	return false, fmt.Errorf("unsupported type: %s.%s", t.PkgPath(), t.Name())
@@ -274,13 +663,15 @@ func StringPtr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(&val))
+	p := reflect.New(v.Type().Elem())
+	p.Elem().SetString(val)
+	v.Set(p)
 	return true, nil
 }
 
 // BoolPtr sets a bool pointer.
 func BoolPtr(v reflect.Value, val string) (bool, error) {
-	b, err := strconv.ParseBool(val)
+	b, err := ParseBool(val)
 	if err != nil {
 		return false, err
 	}
@@ -289,7 +680,9 @@ func BoolPtr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(&b))
+	p := reflect.New(v.Type().Elem())
+	p.Elem().SetBool(b)
+	v.Set(p)
 	return true, nil
 }
 
@@ -304,8 +697,7 @@ func Float32Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	f32 := float32(f64)
-	v.Set(reflect.ValueOf(&f32))
+	setFloatPtr(v, float64(float32(f64)))
 	return true, nil
 }
 
@@ -320,14 +712,14 @@ func Float64Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(&f64))
+	setFloatPtr(v, f64)
 	return true, nil
 }
 
 // IntPtr sets an int pointer.
-func IntPtr(v reflect.Value, val string) (bool, error) {
+func IntPtr(v reflect.Value, val string, opts ...Option) (bool, error) {
 	// int size and range are platform-dependent
-	i64, err := strconv.ParseInt(val, 10, 64)
+	i64, err := parseInt(val, 64, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -336,14 +728,13 @@ func IntPtr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	i := int(i64)
-	v.Set(reflect.ValueOf(&i))
+	setIntPtr(v, i64)
 	return true, nil
 }
 
 // Int8Ptr sets an int8 pointer.
-func Int8Ptr(v reflect.Value, val string) (bool, error) {
-	i64, err := strconv.ParseInt(val, 10, 8)
+func Int8Ptr(v reflect.Value, val string, opts ...Option) (bool, error) {
+	i64, err := parseInt(val, 8, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -352,14 +743,13 @@ func Int8Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	i8 := int8(i64)
-	v.Set(reflect.ValueOf(&i8))
+	setIntPtr(v, i64)
 	return true, nil
 }
 
 // Int16Ptr sets an int16 pointer.
-func Int16Ptr(v reflect.Value, val string) (bool, error) {
-	i64, err := strconv.ParseInt(val, 10, 16)
+func Int16Ptr(v reflect.Value, val string, opts ...Option) (bool, error) {
+	i64, err := parseInt(val, 16, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -368,14 +758,13 @@ func Int16Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	i16 := int16(i64)
-	v.Set(reflect.ValueOf(&i16))
+	setIntPtr(v, i64)
 	return true, nil
 }
 
 // Int32Ptr sets an int32 pointer.
-func Int32Ptr(v reflect.Value, val string) (bool, error) {
-	i64, err := strconv.ParseInt(val, 10, 32)
+func Int32Ptr(v reflect.Value, val string, opts ...Option) (bool, error) {
+	i64, err := parseInt(val, 32, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -384,17 +773,16 @@ func Int32Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	i32 := int32(i64)
-	v.Set(reflect.ValueOf(&i32))
+	setIntPtr(v, i64)
 	return true, nil
 }
 
 // Int64Ptr sets an int64 pointer.
-func Int64Ptr(v reflect.Value, val string) (bool, error) {
+func Int64Ptr(v reflect.Value, val string, opts ...Option) (bool, error) {
 	t := reflect.TypeOf(v.Interface()).Elem()
 
 	if t.PkgPath() == "time" && t.Name() == "Duration" {
-		d, err := time.ParseDuration(val)
+		d, err := parseDuration(val)
 		if err != nil {
 			return false, err
 		}
@@ -407,7 +795,7 @@ func Int64Ptr(v reflect.Value, val string) (bool, error) {
 		return true, nil
 	}
 
-	i64, err := strconv.ParseInt(val, 10, 64)
+	i64, err := parseInt(val, 64, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -416,14 +804,14 @@ func Int64Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(&i64))
+	setIntPtr(v, i64)
 	return true, nil
 }
 
 // UintPtr sets an uint pointer.
-func UintPtr(v reflect.Value, val string) (bool, error) {
+func UintPtr(v reflect.Value, val string, opts ...Option) (bool, error) {
 	// uint size and range are platform-dependent
-	u64, err := strconv.ParseUint(val, 10, 64)
+	u64, err := parseUint(val, 64, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -432,14 +820,13 @@ func UintPtr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	u := uint(u64)
-	v.Set(reflect.ValueOf(&u))
+	setUintPtr(v, u64)
 	return true, nil
 }
 
 // Uint8Ptr sets an uint8 pointer.
-func Uint8Ptr(v reflect.Value, val string) (bool, error) {
-	u64, err := strconv.ParseUint(val, 10, 8)
+func Uint8Ptr(v reflect.Value, val string, opts ...Option) (bool, error) {
+	u64, err := parseUint(val, 8, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -448,14 +835,13 @@ func Uint8Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	u8 := uint8(u64)
-	v.Set(reflect.ValueOf(&u8))
+	setUintPtr(v, u64)
 	return true, nil
 }
 
 // Uint16Ptr sets an uint16 pointer.
-func Uint16Ptr(v reflect.Value, val string) (bool, error) {
-	u64, err := strconv.ParseUint(val, 10, 16)
+func Uint16Ptr(v reflect.Value, val string, opts ...Option) (bool, error) {
+	u64, err := parseUint(val, 16, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -464,14 +850,13 @@ func Uint16Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	u16 := uint16(u64)
-	v.Set(reflect.ValueOf(&u16))
+	setUintPtr(v, u64)
 	return true, nil
 }
 
 // Uint32Ptr sets an uint32 pointer.
-func Uint32Ptr(v reflect.Value, val string) (bool, error) {
-	u64, err := strconv.ParseUint(val, 10, 32)
+func Uint32Ptr(v reflect.Value, val string, opts ...Option) (bool, error) {
+	u64, err := parseUint(val, 32, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -480,14 +865,13 @@ func Uint32Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	u32 := uint32(u64)
-	v.Set(reflect.ValueOf(&u32))
+	setUintPtr(v, u64)
 	return true, nil
 }
 
 // Uint64Ptr sets an uint64 pointer.
-func Uint64Ptr(v reflect.Value, val string) (bool, error) {
-	u64, err := strconv.ParseUint(val, 10, 64)
+func Uint64Ptr(v reflect.Value, val string, opts ...Option) (bool, error) {
+	u64, err := parseUint(val, 64, opts...)
 	if err != nil {
 		return false, err
 	}
@@ -496,7 +880,7 @@ func Uint64Ptr(v reflect.Value, val string) (bool, error) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(&u64))
+	setUintPtr(v, u64)
 	return true, nil
 }
 
@@ -530,6 +914,32 @@ func StructPtr(v reflect.Value, val string) (bool, error) {
 		// r is a pointer
 		v.Set(reflect.ValueOf(r))
 		return true, nil
+	} else if t.PkgPath() == "net" && t.Name() == "IPNet" {
+		n, err := parseCIDR(val)
+		if err != nil {
+			return false, err
+		}
+
+		if !v.IsZero() && reflect.DeepEqual(v.Elem().Interface(), *n) {
+			return false, nil
+		}
+
+		// n is a pointer
+		v.Set(reflect.ValueOf(n))
+		return true, nil
+	} else if t.PkgPath() == "net" && t.Name() == "TCPAddr" {
+		a, err := parseTCPAddr(val)
+		if err != nil {
+			return false, err
+		}
+
+		if !v.IsZero() && reflect.DeepEqual(v.Elem().Interface(), *a) {
+			return false, nil
+		}
+
+		// a is a pointer
+		v.Set(reflect.ValueOf(a))
+		return true, nil
 	}
 
 	return false, fmt.Errorf("unsupported type: %s.%s", t.PkgPath(), t.Name())
@@ -537,11 +947,12 @@ func StructPtr(v reflect.Value, val string) (bool, error) {
 
 // StringSlice sets a string slice.
 func StringSlice(v reflect.Value, vals []string) (bool, error) {
-	if reflect.DeepEqual(v.Interface(), vals) {
+	s := convertSlice(reflect.ValueOf(vals), v.Type())
+	if reflect.DeepEqual(v.Interface(), s.Interface()) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(vals))
+	v.Set(s)
 	return true, nil
 }
 
@@ -549,7 +960,7 @@ func StringSlice(v reflect.Value, vals []string) (bool, error) {
 func BoolSlice(v reflect.Value, vals []string) (bool, error) {
 	bools := []bool{}
 	for _, val := range vals {
-		b, err := strconv.ParseBool(val)
+		b, err := ParseBool(val)
 		if err != nil {
 			return false, err
 		}
@@ -557,11 +968,12 @@ func BoolSlice(v reflect.Value, vals []string) (bool, error) {
 		bools = append(bools, b)
 	}
 
-	if reflect.DeepEqual(v.Interface(), bools) {
+	s := convertSlice(reflect.ValueOf(bools), v.Type())
+	if reflect.DeepEqual(v.Interface(), s.Interface()) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(bools))
+	v.Set(s)
 	return true, nil
 }
 
@@ -577,11 +989,12 @@ func Float32Slice(v reflect.Value, vals []string) (bool, error) {
 		floats = append(floats, float32(f))
 	}
 
-	if reflect.DeepEqual(v.Interface(), floats) {
+	s := convertSlice(reflect.ValueOf(floats), v.Type())
+	if reflect.DeepEqual(v.Interface(), s.Interface()) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(floats))
+	v.Set(s)
 	return true, nil
 }
 
@@ -597,284 +1010,341 @@ func Float64Slice(v reflect.Value, vals []string) (bool, error) {
 		floats = append(floats, f)
 	}
 
-	if reflect.DeepEqual(v.Interface(), floats) {
+	s := convertSlice(reflect.ValueOf(floats), v.Type())
+	if reflect.DeepEqual(v.Interface(), s.Interface()) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(floats))
+	v.Set(s)
 	return true, nil
 }
 
 // IntSlice sets an int slice.
-func IntSlice(v reflect.Value, vals []string) (bool, error) {
+func IntSlice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
 	// int size and range are platform-dependent
-	ints := []int{}
-	for _, val := range vals {
-		i, err := strconv.ParseInt(val, 10, 64)
-		if err != nil {
-			return false, err
-		}
+	return intSlice(v, vals, 64, opts...)
+}
 
-		ints = append(ints, int(i))
-	}
+// Int8Slice sets an int8 slice.
+func Int8Slice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	return intSlice(v, vals, 8, opts...)
+}
 
-	if reflect.DeepEqual(v.Interface(), ints) {
-		return false, nil
-	}
+// Int16Slice sets an int16 slice.
+func Int16Slice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	return intSlice(v, vals, 16, opts...)
+}
 
-	v.Set(reflect.ValueOf(ints))
-	return true, nil
+// Int32Slice sets an int32 slice.
+func Int32Slice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	return intSlice(v, vals, 32, opts...)
 }
 
-// Int8Slice sets an int8 slice.
-func Int8Slice(v reflect.Value, vals []string) (bool, error) {
-	ints := []int8{}
-	for _, val := range vals {
-		i, err := strconv.ParseInt(val, 10, 8)
-		if err != nil {
-			return false, err
+// Int64Slice sets an int64 slice.
+func Int64Slice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	t := reflect.TypeOf(v.Interface()).Elem()
+
+	if t.PkgPath() == "time" && t.Name() == "Duration" {
+		durations := []time.Duration{}
+		for _, val := range vals {
+			d, err := parseDuration(val)
+			if err != nil {
+				return false, err
+			}
+
+			durations = append(durations, d)
 		}
 
-		ints = append(ints, int8(i))
-	}
+		if reflect.DeepEqual(v.Interface(), durations) {
+			return false, nil
+		}
 
-	if reflect.DeepEqual(v.Interface(), ints) {
-		return false, nil
+		v.Set(reflect.ValueOf(durations))
+		return true, nil
 	}
 
-	v.Set(reflect.ValueOf(ints))
-	return true, nil
+	return intSlice(v, vals, 64, opts...)
 }
 
-// Int16Slice sets an int16 slice.
-func Int16Slice(v reflect.Value, vals []string) (bool, error) {
-	ints := []int16{}
-	for _, val := range vals {
-		i, err := strconv.ParseInt(val, 10, 16)
-		if err != nil {
-			return false, err
-		}
+// UintSlice sets an uint slice.
+func UintSlice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	// uint size and range are platform-dependent
+	return uintSlice(v, vals, 64, opts...)
+}
 
-		ints = append(ints, int16(i))
-	}
+// Uint8Slice sets an uint8 slice.
+func Uint8Slice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	return uintSlice(v, vals, 8, opts...)
+}
 
-	if reflect.DeepEqual(v.Interface(), ints) {
-		return false, nil
-	}
+// Uint16Slice sets an uint16 slice.
+func Uint16Slice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	return uintSlice(v, vals, 16, opts...)
+}
 
-	v.Set(reflect.ValueOf(ints))
-	return true, nil
+// Uint32Slice sets an uint32 slice.
+func Uint32Slice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	return uintSlice(v, vals, 32, opts...)
 }
 
-// Int32Slice sets an int32 slice.
-func Int32Slice(v reflect.Value, vals []string) (bool, error) {
-	ints := []int32{}
-	for _, val := range vals {
-		i, err := strconv.ParseInt(val, 10, 32)
-		if err != nil {
-			return false, err
+// Uint64Slice sets an uint64 slice.
+func Uint64Slice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	return uintSlice(v, vals, 64, opts...)
+}
+
+// StructSlice sets a struct slice.
+func StructSlice(v reflect.Value, vals []string) (bool, error) {
+	t := reflect.TypeOf(v.Interface()).Elem()
+
+	if t.PkgPath() == "net/url" && t.Name() == "URL" {
+		urls := []url.URL{}
+		for _, val := range vals {
+			u, err := url.Parse(val)
+			if err != nil {
+				return false, err
+			}
+
+			urls = append(urls, *u)
 		}
 
-		ints = append(ints, int32(i))
-	}
+		// []url.URL
+		if reflect.DeepEqual(v.Interface(), urls) {
+			return false, nil
+		}
 
-	if reflect.DeepEqual(v.Interface(), ints) {
-		return false, nil
-	}
+		v.Set(reflect.ValueOf(urls))
+		return true, nil
+	} else if t.PkgPath() == "regexp" && t.Name() == "Regexp" {
+		regexps := []regexp.Regexp{}
+		for _, val := range vals {
+			r, err := regexp.CompilePOSIX(val)
+			if err != nil {
+				return false, err
+			}
 
-	v.Set(reflect.ValueOf(ints))
-	return true, nil
-}
+			regexps = append(regexps, *r)
+		}
 
-// Int64Slice sets an int64 slice.
-func Int64Slice(v reflect.Value, vals []string) (bool, error) {
-	t := reflect.TypeOf(v.Interface()).Elem()
+		// []regexp.Regexp
+		if reflect.DeepEqual(v.Interface(), regexps) {
+			return false, nil
+		}
 
-	if t.PkgPath() == "time" && t.Name() == "Duration" {
-		durations := []time.Duration{}
+		v.Set(reflect.ValueOf(regexps))
+		return true, nil
+	} else if t.PkgPath() == "net" && t.Name() == "IPNet" {
+		nets := []net.IPNet{}
 		for _, val := range vals {
-			d, err := time.ParseDuration(val)
+			n, err := parseCIDR(val)
 			if err != nil {
 				return false, err
 			}
 
-			durations = append(durations, d)
+			nets = append(nets, *n)
 		}
 
-		if reflect.DeepEqual(v.Interface(), durations) {
+		// []net.IPNet
+		if reflect.DeepEqual(v.Interface(), nets) {
 			return false, nil
 		}
 
-		v.Set(reflect.ValueOf(durations))
+		v.Set(reflect.ValueOf(nets))
 		return true, nil
-	}
+	} else if t.PkgPath() == "net" && t.Name() == "TCPAddr" {
+		addrs := []net.TCPAddr{}
+		for _, val := range vals {
+			a, err := parseTCPAddr(val)
+			if err != nil {
+				return false, err
+			}
 
-	ints := []int64{}
-	for _, val := range vals {
-		i, err := strconv.ParseInt(val, 10, 64)
-		if err != nil {
-			return false, err
+			addrs = append(addrs, *a)
+		}
+
+		// []net.TCPAddr
+		if reflect.DeepEqual(v.Interface(), addrs) {
+			return false, nil
 		}
 
-		ints = append(ints, i)
+		v.Set(reflect.ValueOf(addrs))
+		return true, nil
+	}
+
+	return false, fmt.Errorf("unsupported type: %s.%s", t.PkgPath(), t.Name())
+}
+
+// HardwareAddr sets a net.HardwareAddr value.
+func HardwareAddr(v reflect.Value, val string) (bool, error) {
+	a, err := net.ParseMAC(val)
+	if err != nil {
+		return false, err
 	}
 
-	if reflect.DeepEqual(v.Interface(), ints) {
+	if reflect.DeepEqual(v.Interface(), a) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(ints))
+	v.Set(reflect.ValueOf(a))
 	return true, nil
 }
 
-// UintSlice sets an uint slice.
-func UintSlice(v reflect.Value, vals []string) (bool, error) {
-	// uint size and range are platform-dependent
-	uints := []uint{}
-	for _, val := range vals {
-		u, err := strconv.ParseUint(val, 10, 64)
-		if err != nil {
-			return false, err
-		}
-
-		uints = append(uints, uint(u))
+// HardwareAddrPtr sets a net.HardwareAddr pointer.
+func HardwareAddrPtr(v reflect.Value, val string) (bool, error) {
+	a, err := net.ParseMAC(val)
+	if err != nil {
+		return false, err
 	}
 
-	if reflect.DeepEqual(v.Interface(), uints) {
+	if !v.IsZero() && reflect.DeepEqual(v.Elem().Interface(), a) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(uints))
+	v.Set(reflect.ValueOf(&a))
 	return true, nil
 }
 
-// Uint8Slice sets an uint8 slice.
-func Uint8Slice(v reflect.Value, vals []string) (bool, error) {
-	uints := []uint8{}
+// HardwareAddrSlice sets a net.HardwareAddr slice.
+func HardwareAddrSlice(v reflect.Value, vals []string) (bool, error) {
+	addrs := []net.HardwareAddr{}
 	for _, val := range vals {
-		u, err := strconv.ParseUint(val, 10, 8)
+		a, err := net.ParseMAC(val)
 		if err != nil {
 			return false, err
 		}
 
-		uints = append(uints, uint8(u))
+		addrs = append(addrs, a)
 	}
 
-	if reflect.DeepEqual(v.Interface(), uints) {
+	if reflect.DeepEqual(v.Interface(), addrs) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(uints))
+	v.Set(reflect.ValueOf(addrs))
 	return true, nil
 }
 
-// Uint16Slice sets an uint16 slice.
-func Uint16Slice(v reflect.Value, vals []string) (bool, error) {
-	uints := []uint16{}
-	for _, val := range vals {
-		u, err := strconv.ParseUint(val, 10, 16)
-		if err != nil {
-			return false, err
-		}
-
-		uints = append(uints, uint16(u))
+// Time sets a time.Time value.
+func Time(v reflect.Value, val string, opts ...Option) (bool, error) {
+	t, err := parseTime(val, opts...)
+	if err != nil {
+		return false, err
 	}
 
-	if reflect.DeepEqual(v.Interface(), uints) {
+	if curr := v.Interface().(time.Time); curr.Equal(t) && curr.Location() == t.Location() {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(uints))
+	v.Set(reflect.ValueOf(t))
 	return true, nil
 }
 
-// Uint32Slice sets an uint32 slice.
-func Uint32Slice(v reflect.Value, vals []string) (bool, error) {
-	uints := []uint32{}
-	for _, val := range vals {
-		u, err := strconv.ParseUint(val, 10, 32)
-		if err != nil {
-			return false, err
-		}
-
-		uints = append(uints, uint32(u))
+// TimePtr sets a time.Time pointer.
+func TimePtr(v reflect.Value, val string, opts ...Option) (bool, error) {
+	t, err := parseTime(val, opts...)
+	if err != nil {
+		return false, err
 	}
 
-	if reflect.DeepEqual(v.Interface(), uints) {
-		return false, nil
+	if !v.IsZero() {
+		if curr := v.Elem().Interface().(time.Time); curr.Equal(t) && curr.Location() == t.Location() {
+			return false, nil
+		}
 	}
 
-	v.Set(reflect.ValueOf(uints))
+	v.Set(reflect.ValueOf(&t))
 	return true, nil
 }
 
-// Uint64Slice sets an uint64 slice.
-func Uint64Slice(v reflect.Value, vals []string) (bool, error) {
-	uints := []uint64{}
+// TimeSlice sets a time.Time slice.
+func TimeSlice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	times := []time.Time{}
 	for _, val := range vals {
-		u, err := strconv.ParseUint(val, 10, 64)
+		t, err := parseTime(val, opts...)
 		if err != nil {
 			return false, err
 		}
 
-		uints = append(uints, u)
+		times = append(times, t)
 	}
 
-	if reflect.DeepEqual(v.Interface(), uints) {
+	if reflect.DeepEqual(v.Interface(), times) {
 		return false, nil
 	}
 
-	v.Set(reflect.ValueOf(uints))
+	v.Set(reflect.ValueOf(times))
 	return true, nil
 }
 
-// StructSlice sets a struct slice.
-func StructSlice(v reflect.Value, vals []string) (bool, error) {
-	t := reflect.TypeOf(v.Interface()).Elem()
+// Custom sets a value of a custom type (see IsCustomType).
+func Custom(v reflect.Value, val string) (bool, error) {
+	ptr := reflect.New(v.Type())
+	if err := unmarshal(ptr, val); err != nil {
+		return false, err
+	}
 
-	if t.PkgPath() == "net/url" && t.Name() == "URL" {
-		urls := []url.URL{}
-		for _, val := range vals {
-			u, err := url.Parse(val)
-			if err != nil {
-				return false, err
-			}
+	if reflect.DeepEqual(v.Interface(), ptr.Elem().Interface()) {
+		return false, nil
+	}
 
-			urls = append(urls, *u)
-		}
+	v.Set(ptr.Elem())
+	return true, nil
+}
 
-		// []url.URL
-		if reflect.DeepEqual(v.Interface(), urls) {
-			return false, nil
-		}
+// CustomPtr sets a pointer to a custom type (see IsCustomType).
+func CustomPtr(v reflect.Value, val string) (bool, error) {
+	ptr := reflect.New(v.Type().Elem())
+	if err := unmarshal(ptr, val); err != nil {
+		return false, err
+	}
 
-		v.Set(reflect.ValueOf(urls))
-		return true, nil
-	} else if t.PkgPath() == "regexp" && t.Name() == "Regexp" {
-		regexps := []regexp.Regexp{}
-		for _, val := range vals {
-			r, err := regexp.CompilePOSIX(val)
-			if err != nil {
-				return false, err
-			}
+	if !v.IsZero() && reflect.DeepEqual(v.Elem().Interface(), ptr.Elem().Interface()) {
+		return false, nil
+	}
 
-			regexps = append(regexps, *r)
-		}
+	v.Set(ptr)
+	return true, nil
+}
 
-		// []regexp.Regexp
-		if reflect.DeepEqual(v.Interface(), regexps) {
-			return false, nil
+// CustomSlice sets a slice of a custom type or pointers to a custom type (see IsCustomType).
+func CustomSlice(v reflect.Value, vals []string) (bool, error) {
+	s := reflect.MakeSlice(v.Type(), 0, len(vals))
+	for _, val := range vals {
+		e := reflect.New(v.Type().Elem()).Elem()
+		if _, err := Value(e, "", val); err != nil {
+			return false, err
 		}
 
-		v.Set(reflect.ValueOf(regexps))
-		return true, nil
+		s = reflect.Append(s, e)
 	}
 
-	return false, fmt.Errorf("unsupported type: %s.%s", t.PkgPath(), t.Name())
+	if reflect.DeepEqual(v.Interface(), s.Interface()) {
+		return false, nil
+	}
+
+	v.Set(s)
+	return true, nil
 }
 
 // Value sets a supported value.
-func Value(v reflect.Value, sep, val string) (bool, error) {
+func Value(v reflect.Value, sep, val string, opts ...Option) (bool, error) {
+	if k := v.Kind(); k != reflect.Slice && k != reflect.Map {
+		var err error
+		if val, err = resolveEnum(val, enumChoices(v.Type(), opts...)); err != nil {
+			return false, err
+		}
+	}
+
+	if IsCustomType(v.Type()) {
+		return Custom(v, val)
+	}
+
+	if t := v.Type(); isInteger(t) || (t.Kind() == reflect.Ptr && isInteger(t.Elem())) {
+		var err error
+		if val, err = applyUnit(val, newOptions(opts...).unit); err != nil {
+			return false, err
+		}
+	}
+
 	switch v.Kind() {
 	case reflect.String:
 		return String(v, val)
@@ -885,31 +1355,45 @@ func Value(v reflect.Value, sep, val string) (bool, error) {
 	case reflect.Float64:
 		return Float64(v, val)
 	case reflect.Int:
-		return Int(v, val)
+		return Int(v, val, opts...)
 	case reflect.Int8:
-		return Int8(v, val)
+		return Int8(v, val, opts...)
 	case reflect.Int16:
-		return Int16(v, val)
+		return Int16(v, val, opts...)
 	case reflect.Int32:
-		return Int32(v, val)
+		return Int32(v, val, opts...)
 	case reflect.Int64:
-		return Int64(v, val)
+		return Int64(v, val, opts...)
 	case reflect.Uint:
-		return Uint(v, val)
+		return Uint(v, val, opts...)
 	case reflect.Uint8:
-		return Uint8(v, val)
+		return Uint8(v, val, opts...)
 	case reflect.Uint16:
-		return Uint16(v, val)
+		return Uint16(v, val, opts...)
 	case reflect.Uint32:
-		return Uint32(v, val)
+		if isFileModeType(v.Type()) {
+			return FileMode(v, val, opts...)
+		}
+		return Uint32(v, val, opts...)
 	case reflect.Uint64:
-		return Uint64(v, val)
+		return Uint64(v, val, opts...)
 	case reflect.Struct:
+		if isTimeType(v.Type()) {
+			return Time(v, val, opts...)
+		}
 		return Struct(v, val)
 
 	case reflect.Ptr:
 		tPtr := reflect.TypeOf(v.Interface()).Elem()
 
+		if IsCustomType(tPtr) {
+			return CustomPtr(v, val)
+		}
+
+		if isHardwareAddrType(tPtr) {
+			return HardwareAddrPtr(v, val)
+		}
+
 		switch tPtr.Kind() {
 		case reflect.String:
 			return StringPtr(v, val)
@@ -920,66 +1404,237 @@ func Value(v reflect.Value, sep, val string) (bool, error) {
 		case reflect.Float64:
 			return Float64Ptr(v, val)
 		case reflect.Int:
-			return IntPtr(v, val)
+			return IntPtr(v, val, opts...)
 		case reflect.Int8:
-			return Int8Ptr(v, val)
+			return Int8Ptr(v, val, opts...)
 		case reflect.Int16:
-			return Int16Ptr(v, val)
+			return Int16Ptr(v, val, opts...)
 		case reflect.Int32:
-			return Int32Ptr(v, val)
+			return Int32Ptr(v, val, opts...)
 		case reflect.Int64:
-			return Int64Ptr(v, val)
+			return Int64Ptr(v, val, opts...)
 		case reflect.Uint:
-			return UintPtr(v, val)
+			return UintPtr(v, val, opts...)
 		case reflect.Uint8:
-			return Uint8Ptr(v, val)
+			return Uint8Ptr(v, val, opts...)
 		case reflect.Uint16:
-			return Uint16Ptr(v, val)
+			return Uint16Ptr(v, val, opts...)
 		case reflect.Uint32:
-			return Uint32Ptr(v, val)
+			if isFileModeType(tPtr) {
+				return FileModePtr(v, val, opts...)
+			}
+			return Uint32Ptr(v, val, opts...)
 		case reflect.Uint64:
-			return Uint64Ptr(v, val)
+			return Uint64Ptr(v, val, opts...)
 		case reflect.Struct:
+			if isTimeType(tPtr) {
+				return TimePtr(v, val, opts...)
+			}
 			return StructPtr(v, val)
 		}
 
 	case reflect.Slice:
-		tSlice := reflect.TypeOf(v.Interface()).Elem()
-		vals := strings.Split(val, sep)
+		if isHardwareAddrType(v.Type()) {
+			return HardwareAddr(v, val)
+		}
+		return Values(v, strings.Split(val, sep), opts...)
+	case reflect.Map:
+		return Map(v, splitPairs(val, sep), opts...)
+	}
 
-		switch tSlice.Kind() {
-		case reflect.String:
-			return StringSlice(v, vals)
-		case reflect.Bool:
-			return BoolSlice(v, vals)
-		case reflect.Float32:
-			return Float32Slice(v, vals)
-		case reflect.Float64:
-			return Float64Slice(v, vals)
-		case reflect.Int:
-			return IntSlice(v, vals)
-		case reflect.Int8:
-			return Int8Slice(v, vals)
-		case reflect.Int16:
-			return Int16Slice(v, vals)
-		case reflect.Int32:
-			return Int32Slice(v, vals)
-		case reflect.Int64:
-			return Int64Slice(v, vals)
-		case reflect.Uint:
-			return UintSlice(v, vals)
-		case reflect.Uint8:
-			return Uint8Slice(v, vals)
-		case reflect.Uint16:
-			return Uint16Slice(v, vals)
-		case reflect.Uint32:
-			return Uint32Slice(v, vals)
-		case reflect.Uint64:
-			return Uint64Slice(v, vals)
-		case reflect.Struct:
-			return StructSlice(v, vals)
+	return false, fmt.Errorf("unsupported kind: %s", v.Kind())
+}
+
+// elementSlice sets a slice by setting every element separately using Value.
+// It is used for the slices of enum types and pointer types ([]*net.TCPAddr).
+func elementSlice(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	s := reflect.MakeSlice(v.Type(), 0, len(vals))
+	for _, val := range vals {
+		e := reflect.New(v.Type().Elem()).Elem()
+		if _, err := Value(e, "", val, opts...); err != nil {
+			return false, err
+		}
+
+		s = reflect.Append(s, e)
+	}
+
+	if reflect.DeepEqual(v.Interface(), s.Interface()) {
+		return false, nil
+	}
+
+	v.Set(s)
+	return true, nil
+}
+
+// Values sets a supported slice value from a list of string values.
+func Values(v reflect.Value, vals []string, opts ...Option) (bool, error) {
+	if v.Kind() != reflect.Slice {
+		return false, fmt.Errorf("unsupported kind: %s", v.Kind())
+	}
+
+	tSlice := reflect.TypeOf(v.Interface()).Elem()
+
+	if len(enumChoices(tSlice, opts...)) > 0 {
+		return elementSlice(v, vals, opts...)
+	}
+
+	if IsCustomType(tSlice) || (tSlice.Kind() == reflect.Ptr && IsCustomType(tSlice.Elem())) {
+		return CustomSlice(v, vals)
+	}
+
+	if isHardwareAddrType(tSlice) {
+		return HardwareAddrSlice(v, vals)
+	}
+
+	if tSlice.Kind() == reflect.Ptr {
+		return elementSlice(v, vals, opts...)
+	}
+
+	if unit := newOptions(opts...).unit; unit != "" && isInteger(tSlice) {
+		converted := make([]string, len(vals))
+		for i, val := range vals {
+			var err error
+			if converted[i], err = applyUnit(val, unit); err != nil {
+				return false, err
+			}
 		}
+		vals = converted
+	}
+
+	switch tSlice.Kind() {
+	case reflect.String:
+		return StringSlice(v, vals)
+	case reflect.Bool:
+		return BoolSlice(v, vals)
+	case reflect.Float32:
+		return Float32Slice(v, vals)
+	case reflect.Float64:
+		return Float64Slice(v, vals)
+	case reflect.Int:
+		return IntSlice(v, vals, opts...)
+	case reflect.Int8:
+		return Int8Slice(v, vals, opts...)
+	case reflect.Int16:
+		return Int16Slice(v, vals, opts...)
+	case reflect.Int32:
+		return Int32Slice(v, vals, opts...)
+	case reflect.Int64:
+		return Int64Slice(v, vals, opts...)
+	case reflect.Uint:
+		return UintSlice(v, vals, opts...)
+	case reflect.Uint8:
+		return Uint8Slice(v, vals, opts...)
+	case reflect.Uint16:
+		return Uint16Slice(v, vals, opts...)
+	case reflect.Uint32:
+		if isFileModeType(tSlice) {
+			return FileModeSlice(v, vals, opts...)
+		}
+		return Uint32Slice(v, vals, opts...)
+	case reflect.Uint64:
+		return Uint64Slice(v, vals, opts...)
+	case reflect.Struct:
+		if isTimeType(tSlice) {
+			return TimeSlice(v, vals, opts...)
+		}
+		return StructSlice(v, vals)
 	}
 
 	return false, fmt.Errorf("unsupported kind: %s", v.Kind())
 }
+
+// Append appends to a supported slice value.
+// The given value is split by the separator, parsed to the element type of the slice, and appended to the current elements.
+func Append(v reflect.Value, sep, val string, opts ...Option) (bool, error) {
+	tmp := reflect.New(v.Type()).Elem()
+	if _, err := Values(tmp, strings.Split(val, sep), opts...); err != nil {
+		return false, err
+	}
+
+	v.Set(reflect.AppendSlice(v, tmp))
+	return true, nil
+}
+
+// Map sets a supported map value from a list of key-value pairs.
+func Map(v reflect.Value, pairs []string, opts ...Option) (bool, error) {
+	m, err := parseMap(v.Type(), pairs, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	if reflect.DeepEqual(v.Interface(), m.Interface()) {
+		return false, nil
+	}
+
+	v.Set(m)
+	return true, nil
+}
+
+// Merge merges into a supported map value.
+// The given value is split by the separator into key-value pairs, parsed to the key and element types of the map, and added to the current entries.
+// The entries with an existing key replace the current ones.
+func Merge(v reflect.Value, sep, val string, opts ...Option) (bool, error) {
+	if v.Kind() != reflect.Map {
+		return false, fmt.Errorf("unsupported kind: %s", v.Kind())
+	}
+
+	m, err := parseMap(v.Type(), splitPairs(val, sep), opts...)
+	if err != nil {
+		return false, err
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	iter := m.MapRange()
+	for iter.Next() {
+		v.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	return true, nil
+}
+
+// splitPairs splits a string value into key-value pairs.
+// An empty string value has no key-value pairs.
+func splitPairs(val, sep string) []string {
+	if val == "" {
+		return []string{}
+	}
+
+	return strings.Split(val, sep)
+}
+
+// parseMap parses a list of key-value pairs into a new map of the given type.
+func parseMap(t reflect.Type, pairs []string, opts ...Option) (reflect.Value, error) {
+	if t.Kind() != reflect.Map {
+		return reflect.Value{}, fmt.Errorf("unsupported kind: %s", t.Kind())
+	}
+
+	o := newOptions(opts...)
+	m := reflect.MakeMapWithSize(t, len(pairs))
+
+	// The enum option is only for the elements
+	keyOpts := append(append([]Option{}, opts...), Enum())
+
+	for _, pair := range pairs {
+		i := strings.Index(pair, o.kvSep)
+		if i < 0 {
+			return reflect.Value{}, fmt.Errorf("invalid key-value pair: %q", pair)
+		}
+
+		key := reflect.New(t.Key()).Elem()
+		if _, err := Value(key, "", pair[:i], keyOpts...); err != nil {
+			return reflect.Value{}, err
+		}
+
+		elem := reflect.New(t.Elem()).Elem()
+		if _, err := Value(elem, "", pair[i+len(o.kvSep):], opts...); err != nil {
+			return reflect.Value{}, err
+		}
+
+		m.SetMapIndex(key, elem)
+	}
+
+	return m, nil
+}
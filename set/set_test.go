@@ -1,9 +1,13 @@
 package set
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"net/url"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +15,40 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+type logLevel int
+
+func (l *logLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "error":
+		*l = 2
+	default:
+		return errors.New("invalid log level")
+	}
+
+	return nil
+}
+
+type csv struct {
+	fields []string
+}
+
+func (c *csv) String() string {
+	return strings.Join(c.fields, ",")
+}
+
+func (c *csv) Set(val string) error {
+	if val == "" {
+		return errors.New("empty value")
+	}
+
+	c.fields = append(c.fields, strings.Split(val, ";")...)
+	return nil
+}
+
 func TestString(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -2830,3 +2868,1421 @@ func TestValue(t *testing.T) {
 		})
 	}
 }
+
+func TestValues(t *testing.T) {
+	url1, _ := url.Parse("service-1")
+	url2, _ := url.Parse("service-2")
+
+	tests := []struct {
+		name            string
+		s               interface{}
+		vals            []string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"NonSlice",
+			ptr.String("foo"),
+			[]string{"bar"},
+			false, "unsupported kind: string",
+			ptr.String("foo"),
+		},
+		{
+			"UnsupportedSlice",
+			&[]complex64{},
+			[]string{"1+2i"},
+			false, "unsupported kind: slice",
+			&[]complex64{},
+		},
+		{
+			"StringSlice",
+			&[]string{"foo"},
+			[]string{"a,b", "c"},
+			true, "",
+			&[]string{"a,b", "c"},
+		},
+		{
+			"IntSlice",
+			&[]int{},
+			[]string{"1", "2"},
+			true, "",
+			&[]int{1, 2},
+		},
+		{
+			"URLSlice",
+			&[]url.URL{},
+			[]string{"service-1", "service-2"},
+			true, "",
+			&[]url.URL{*url1, *url2},
+		},
+		{
+			"IntPtrSlice",
+			&[]*int{},
+			[]string{"1", "2"},
+			true, "",
+			&[]*int{ptr.Int(1), ptr.Int(2)},
+		},
+		{
+			"TCPAddrPtrSlice",
+			&[]*net.TCPAddr{},
+			[]string{"127.0.0.1:8080", ":9090"},
+			true, "",
+			&[]*net.TCPAddr{
+				{IP: net.IPv4(127, 0, 0, 1), Port: 8080},
+				{Port: 9090},
+			},
+		},
+		{
+			"IPNetPtrSlice",
+			&[]*net.IPNet{},
+			[]string{"10.0.0.0/8"},
+			true, "",
+			&[]*net.IPNet{
+				{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)},
+			},
+		},
+		{
+			"InvalidPtrValue",
+			&[]*net.TCPAddr{},
+			[]string{"invalid"},
+			false, "address invalid: missing port in address",
+			&[]*net.TCPAddr{},
+		},
+		{
+			"InvalidValue",
+			&[]int{1},
+			[]string{"invalid"},
+			false, `strconv.ParseInt: parsing "invalid": invalid syntax`,
+			&[]int{1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Values(v, tc.vals)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	url1, _ := url.Parse("service-1")
+	url2, _ := url.Parse("service-2")
+	url3, _ := url.Parse("service-3")
+
+	tests := []struct {
+		name            string
+		s               interface{}
+		sep             string
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"NonSlice",
+			ptr.String("foo"),
+			",", "bar",
+			false, "unsupported kind: string",
+			ptr.String("foo"),
+		},
+		{
+			"StringSlice_Empty",
+			&[]string{},
+			",", "foo",
+			true, "",
+			&[]string{"foo"},
+		},
+		{
+			"StringSlice",
+			&[]string{"foo"},
+			",", "bar,baz",
+			true, "",
+			&[]string{"foo", "bar", "baz"},
+		},
+		{
+			"IntSlice",
+			&[]int{1},
+			"|", "2|3",
+			true, "",
+			&[]int{1, 2, 3},
+		},
+		{
+			"URLSlice",
+			&[]url.URL{*url1},
+			",", "service-2,service-3",
+			true, "",
+			&[]url.URL{*url1, *url2, *url3},
+		},
+		{
+			"InvalidValue",
+			&[]int{1},
+			",", "invalid",
+			false, `strconv.ParseInt: parsing "invalid": invalid syntax`,
+			&[]int{1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Append(v, tc.sep, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		pairs           []string
+		opts            []Option
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"NonMap",
+			ptr.String("foo"),
+			[]string{"env=prod"},
+			nil,
+			false, "unsupported kind: string",
+			ptr.String("foo"),
+		},
+		{
+			"StringMap",
+			&map[string]string{"team": "core"},
+			[]string{"env=prod", "tier=web=frontend"},
+			nil,
+			true, "",
+			&map[string]string{"env": "prod", "tier": "web=frontend"},
+		},
+		{
+			"StringMap_NoChange",
+			&map[string]string{"env": "prod"},
+			[]string{"env=prod"},
+			nil,
+			false, "",
+			&map[string]string{"env": "prod"},
+		},
+		{
+			"IntMap_KeyValueSeparator",
+			&map[string]int{},
+			[]string{"cpu:2", "memory:512"},
+			[]Option{KeyValueSeparator(":")},
+			true, "",
+			&map[string]int{"cpu": 2, "memory": 512},
+		},
+		{
+			"UintKeyMap",
+			&map[uint16]bool{},
+			[]string{"80=true", "443=false"},
+			nil,
+			true, "",
+			&map[uint16]bool{80: true, 443: false},
+		},
+		{
+			"DurationMap",
+			&map[string]time.Duration{},
+			[]string{"read=1s", "write=1m"},
+			nil,
+			true, "",
+			&map[string]time.Duration{"read": time.Second, "write": time.Minute},
+		},
+		{
+			"InvalidPair",
+			&map[string]string{},
+			[]string{"env"},
+			nil,
+			false, `invalid key-value pair: "env"`,
+			&map[string]string{},
+		},
+		{
+			"InvalidKey",
+			&map[int]string{},
+			[]string{"one=1"},
+			nil,
+			false, `strconv.ParseInt: parsing "one": invalid syntax`,
+			&map[int]string{},
+		},
+		{
+			"InvalidValue",
+			&map[string]float64{},
+			[]string{"pi=three"},
+			nil,
+			false, `strconv.ParseFloat: parsing "three": invalid syntax`,
+			&map[string]float64{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Map(v, tc.pairs, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		sep             string
+		val             string
+		opts            []Option
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"NonMap",
+			&[]string{"foo"},
+			",", "env=prod",
+			nil,
+			false, "unsupported kind: slice",
+			&[]string{"foo"},
+		},
+		{
+			"NilMap",
+			new(map[string]string),
+			",", "env=prod",
+			nil,
+			true, "",
+			&map[string]string{"env": "prod"},
+		},
+		{
+			"StringMap",
+			&map[string]string{"env": "dev", "team": "core"},
+			",", "env=prod,tier=web",
+			nil,
+			true, "",
+			&map[string]string{"env": "prod", "team": "core", "tier": "web"},
+		},
+		{
+			"IntMap",
+			&map[string]int{"cpu": 1},
+			";", "memory:512;disk:10",
+			[]Option{KeyValueSeparator(":")},
+			true, "",
+			&map[string]int{"cpu": 1, "memory": 512, "disk": 10},
+		},
+		{
+			"InvalidPair",
+			&map[string]string{"env": "dev"},
+			",", "tier=web,env",
+			nil,
+			false, `invalid key-value pair: "env"`,
+			&map[string]string{"env": "dev"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Merge(v, tc.sep, tc.val, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestIsCustomType(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected bool
+	}{
+		{"String", "", false},
+		{"Int", 0, false},
+		{"URL", url.URL{}, false},
+		{"Regexp", regexp.Regexp{}, false},
+		{"TextUnmarshaler", logLevel(0), true},
+		{"FlagValue", csv{}, true},
+		{"Pointer", new(logLevel), false},
+		{"Time", time.Time{}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			typ := reflect.TypeOf(tc.value)
+
+			assert.Equal(t, tc.expected, IsCustomType(typ))
+		})
+	}
+}
+
+func TestCustom(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"TextUnmarshaler",
+			new(logLevel),
+			"error",
+			true, "",
+			func() *logLevel { l := logLevel(2); return &l }(),
+		},
+		{
+			"TextUnmarshaler_NoChange",
+			new(logLevel),
+			"debug",
+			false, "",
+			new(logLevel),
+		},
+		{
+			"TextUnmarshaler_Invalid",
+			new(logLevel),
+			"trace",
+			false, "invalid log level",
+			new(logLevel),
+		},
+		{
+			"FlagValue",
+			&csv{fields: []string{"x"}},
+			"a;b",
+			true, "",
+			&csv{fields: []string{"a", "b"}},
+		},
+		{
+			"FlagValue_Invalid",
+			&csv{fields: []string{"x"}},
+			"",
+			false, "empty value",
+			&csv{fields: []string{"x"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Custom(v, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestCustomPtr(t *testing.T) {
+	info := logLevel(1)
+
+	tests := []struct {
+		name            string
+		s               interface{}
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"Nil",
+			new(*logLevel),
+			"info",
+			true, "",
+			func() **logLevel { l := &info; return &l }(),
+		},
+		{
+			"NoChange",
+			func() **logLevel { l := logLevel(1); p := &l; return &p }(),
+			"info",
+			false, "",
+			func() **logLevel { l := &info; return &l }(),
+		},
+		{
+			"Invalid",
+			new(*logLevel),
+			"trace",
+			false, "invalid log level",
+			new(*logLevel),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := CustomPtr(v, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestCustomSlice(t *testing.T) {
+	info, errorLevel := logLevel(1), logLevel(2)
+
+	tests := []struct {
+		name            string
+		s               interface{}
+		vals            []string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"Values",
+			&[]logLevel{},
+			[]string{"info", "error"},
+			true, "",
+			&[]logLevel{1, 2},
+		},
+		{
+			"Pointers",
+			&[]*logLevel{},
+			[]string{"info", "error"},
+			true, "",
+			&[]*logLevel{&info, &errorLevel},
+		},
+		{
+			"NoChange",
+			&[]logLevel{1},
+			[]string{"info"},
+			false, "",
+			&[]logLevel{1},
+		},
+		{
+			"Invalid",
+			&[]logLevel{},
+			[]string{"info", "trace"},
+			false, "invalid log level",
+			&[]logLevel{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := CustomSlice(v, tc.vals)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestValueCustom(t *testing.T) {
+	type spec struct {
+		Level    logLevel
+		LevelPtr *logLevel
+		Levels   []logLevel
+		ByLevel  map[logLevel]string
+		CSV      csv
+		Regexp   regexp.Regexp
+	}
+
+	s := &spec{}
+	v := reflect.ValueOf(s).Elem()
+
+	_, err := Value(v.FieldByName("Level"), ",", "error")
+	assert.NoError(t, err)
+	assert.Equal(t, logLevel(2), s.Level)
+
+	_, err = Value(v.FieldByName("LevelPtr"), ",", "info")
+	assert.NoError(t, err)
+	assert.Equal(t, logLevel(1), *s.LevelPtr)
+
+	_, err = Value(v.FieldByName("Levels"), ",", "debug,error")
+	assert.NoError(t, err)
+	assert.Equal(t, []logLevel{0, 2}, s.Levels)
+
+	_, err = Value(v.FieldByName("ByLevel"), ",", "debug=verbose,error=quiet")
+	assert.NoError(t, err)
+	assert.Equal(t, map[logLevel]string{0: "verbose", 2: "quiet"}, s.ByLevel)
+
+	_, err = Value(v.FieldByName("CSV"), ",", "a;b")
+	assert.NoError(t, err)
+	assert.Equal(t, csv{fields: []string{"a", "b"}}, s.CSV)
+
+	// POSIX syntax is still used for regexp.Regexp
+	_, err = Value(v.FieldByName("Regexp"), ",", "[[:digit:]]+")
+	assert.NoError(t, err)
+	assert.Equal(t, *regexp.MustCompilePOSIX("[[:digit:]]+"), s.Regexp)
+}
+
+type point struct {
+	X, Y int
+}
+
+func parsePoint(val string) (interface{}, error) {
+	var p point
+	if _, err := fmt.Sscanf(val, "%d:%d", &p.X, &p.Y); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func TestRegister(t *testing.T) {
+	pointType := reflect.TypeOf(point{})
+
+	t.Run("Unregistered", func(t *testing.T) {
+		assert.False(t, IsCustomType(pointType))
+
+		p := &point{}
+		_, err := Value(reflect.ValueOf(p).Elem(), ",", "1:2")
+		assert.EqualError(t, err, "unsupported type: github.com/moorara/flagit/set.point")
+	})
+
+	t.Run("Registered", func(t *testing.T) {
+		Register(pointType, parsePoint)
+		defer Register(pointType, nil)
+
+		assert.True(t, IsCustomType(pointType))
+
+		type spec struct {
+			Point   point
+			PointP  *point
+			Points  []point
+			PointsP []*point
+		}
+
+		s := &spec{}
+		v := reflect.ValueOf(s).Elem()
+
+		updated, err := Value(v.FieldByName("Point"), ",", "1:2")
+		assert.NoError(t, err)
+		assert.True(t, updated)
+
+		updated, err = Value(v.FieldByName("Point"), ",", "1:2")
+		assert.NoError(t, err)
+		assert.False(t, updated)
+
+		_, err = Value(v.FieldByName("PointP"), ",", "3:4")
+		assert.NoError(t, err)
+
+		_, err = Value(v.FieldByName("Points"), ",", "1:1,2:2")
+		assert.NoError(t, err)
+
+		_, err = Value(v.FieldByName("PointsP"), ";", "5:5")
+		assert.NoError(t, err)
+
+		assert.Equal(t, &spec{
+			Point:   point{1, 2},
+			PointP:  &point{3, 4},
+			Points:  []point{{1, 1}, {2, 2}},
+			PointsP: []*point{{5, 5}},
+		}, s)
+
+		_, err = Value(v.FieldByName("Point"), ",", "invalid")
+		assert.EqualError(t, err, "expected integer")
+	})
+
+	t.Run("PointerType", func(t *testing.T) {
+		Register(reflect.TypeOf(&point{}), func(val string) (interface{}, error) {
+			p, err := parsePoint(val)
+			if err != nil {
+				return nil, err
+			}
+			pp := p.(point)
+			return &pp, nil
+		})
+		defer Register(pointType, nil)
+
+		p := &point{}
+		_, err := Value(reflect.ValueOf(p).Elem(), ",", "7:8")
+		assert.NoError(t, err)
+		assert.Equal(t, &point{7, 8}, p)
+	})
+
+	t.Run("InvalidParsedValue", func(t *testing.T) {
+		Register(pointType, func(val string) (interface{}, error) {
+			return val, nil
+		})
+		defer Register(pointType, nil)
+
+		p := &point{}
+		_, err := Value(reflect.ValueOf(p).Elem(), ",", "1:2")
+		assert.EqualError(t, err, "invalid parsed value for type set.point: string")
+	})
+
+	t.Run("Precedence", func(t *testing.T) {
+		Register(reflect.TypeOf(logLevel(0)), func(val string) (interface{}, error) {
+			return logLevel(len(val)), nil
+		})
+		defer Register(reflect.TypeOf(logLevel(0)), nil)
+
+		l := new(logLevel)
+		_, err := Value(reflect.ValueOf(l).Elem(), ",", "trace")
+		assert.NoError(t, err)
+		assert.Equal(t, logLevel(5), *l)
+	})
+}
+
+func TestTime(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+
+	tests := []struct {
+		name            string
+		s               interface{}
+		val             string
+		opts            []Option
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"RFC3339",
+			new(time.Time),
+			"2020-05-01T10:30:00Z",
+			nil,
+			true, "",
+			func() *time.Time { t := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC); return &t }(),
+		},
+		{
+			"NoChange",
+			func() *time.Time { t := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC); return &t }(),
+			"2020-05-01T10:30:00Z",
+			nil,
+			false, "",
+			func() *time.Time { t := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC); return &t }(),
+		},
+		{
+			"Layout",
+			new(time.Time),
+			"2020-05-01",
+			[]Option{Layout("2006-01-02")},
+			true, "",
+			func() *time.Time { t := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC); return &t }(),
+		},
+		{
+			"LayoutAndLocation",
+			new(time.Time),
+			"2020-05-01",
+			[]Option{Layout("2006-01-02"), Location(est)},
+			true, "",
+			func() *time.Time { t := time.Date(2020, 5, 1, 0, 0, 0, 0, est); return &t }(),
+		},
+		{
+			"Unix",
+			new(time.Time),
+			"1588329000",
+			nil,
+			true, "",
+			func() *time.Time { t := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC); return &t }(),
+		},
+		{
+			"UnixFraction",
+			new(time.Time),
+			"1588329000.25",
+			nil,
+			true, "",
+			func() *time.Time { t := time.Date(2020, 5, 1, 10, 30, 0, 250000000, time.UTC); return &t }(),
+		},
+		{
+			"UnixNegative",
+			new(time.Time),
+			"-1.5",
+			nil,
+			true, "",
+			func() *time.Time { t := time.Date(1969, 12, 31, 23, 59, 58, 500000000, time.UTC); return &t }(),
+		},
+		{
+			"UnixLocation",
+			new(time.Time),
+			"1588329000",
+			[]Option{Layout("2006-01-02"), Location(est)},
+			true, "",
+			func() *time.Time { t := time.Date(2020, 5, 1, 5, 30, 0, 0, est); return &t }(),
+		},
+		{
+			"DigitsLayout",
+			new(time.Time),
+			"20240102",
+			[]Option{Layout("20060102")},
+			true, "",
+			func() *time.Time { t := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC); return &t }(),
+		},
+		{
+			"YearLayout",
+			new(time.Time),
+			"2024",
+			[]Option{Layout("2006")},
+			true, "",
+			func() *time.Time { t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); return &t }(),
+		},
+		{
+			"UnixWithDigitsLayout",
+			new(time.Time),
+			"1588329000",
+			[]Option{Layout("20060102")},
+			true, "",
+			func() *time.Time { t := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC); return &t }(),
+		},
+		{
+			"Invalid",
+			new(time.Time),
+			"2020-05-01",
+			nil,
+			false, `parsing time "2020-05-01" as "2006-01-02T15:04:05Z07:00": cannot parse "" as "T"`,
+			new(time.Time),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Time(v, tc.val, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestTimePtr(t *testing.T) {
+	t1 := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		s               interface{}
+		val             string
+		opts            []Option
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"Nil",
+			new(*time.Time),
+			"2020-05-01",
+			[]Option{Layout("2006-01-02")},
+			true, "",
+			func() **time.Time { t := &t1; return &t }(),
+		},
+		{
+			"NoChange",
+			func() **time.Time { t := t1; p := &t; return &p }(),
+			"2020-05-01T00:00:00Z",
+			nil,
+			false, "",
+			func() **time.Time { t := &t1; return &t }(),
+		},
+		{
+			"Invalid",
+			new(*time.Time),
+			"yesterday",
+			nil,
+			false, `parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`,
+			new(*time.Time),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := TimePtr(v, tc.val, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestTimeSlice(t *testing.T) {
+	t1 := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		s               interface{}
+		vals            []string
+		opts            []Option
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"NewValue",
+			&[]time.Time{},
+			[]string{"2020-05-01", "2020-05-02"},
+			[]Option{Layout("2006-01-02")},
+			true, "",
+			&[]time.Time{t1, t2},
+		},
+		{
+			"NoChange",
+			&[]time.Time{t1, t2},
+			[]string{"2020-05-01T00:00:00Z", "1588377600"},
+			nil,
+			false, "",
+			&[]time.Time{t1, t2},
+		},
+		{
+			"Invalid",
+			&[]time.Time{},
+			[]string{"2020-05-01", "tomorrow"},
+			[]Option{Layout("2006-01-02")},
+			false, `parsing time "tomorrow" as "2006-01-02": cannot parse "tomorrow" as "2006"`,
+			&[]time.Time{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := TimeSlice(v, tc.vals, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestStructIPNet(t *testing.T) {
+	_, net1, _ := net.ParseCIDR("10.0.0.0/8")
+	_, net2, _ := net.ParseCIDR("192.168.0.0/16")
+
+	tests := []struct {
+		name            string
+		s               net.IPNet
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  net.IPNet
+	}{
+		{
+			"IPNetNewValue",
+			*net1, "192.168.1.1/16",
+			true, "",
+			*net2,
+		},
+		{
+			"IPNetNoNewValue",
+			*net2, "192.168.0.0/16",
+			false, "",
+			*net2,
+		},
+		{
+			"IPNetInvalidValue",
+			*net1, "192.168.0.0",
+			false, "invalid CIDR address: 192.168.0.0",
+			*net1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := Struct(v, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestStructTCPAddr(t *testing.T) {
+	addr1 := net.TCPAddr{Port: 8080}
+	addr2 := net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9090}
+	addr3 := net.TCPAddr{IP: net.ParseIP("fe80::1"), Port: 443, Zone: "eth0"}
+
+	tests := []struct {
+		name            string
+		s               net.TCPAddr
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  net.TCPAddr
+	}{
+		{
+			"TCPAddrNewValue",
+			addr1, "127.0.0.1:9090",
+			true, "",
+			addr2,
+		},
+		{
+			"TCPAddrNoNewValue",
+			addr2, "127.0.0.1:9090",
+			false, "",
+			addr2,
+		},
+		{
+			"TCPAddrEmptyHost",
+			addr2, ":8080",
+			true, "",
+			addr1,
+		},
+		{
+			"TCPAddrIPv6Zone",
+			addr1, "[fe80::1%eth0]:443",
+			true, "",
+			addr3,
+		},
+		{
+			"TCPAddrMissingPort",
+			addr1, "127.0.0.1",
+			false, "address 127.0.0.1: missing port in address",
+			addr1,
+		},
+		{
+			"TCPAddrInvalidPort",
+			addr1, "127.0.0.1:http",
+			false, "invalid port: http",
+			addr1,
+		},
+		{
+			"TCPAddrHostName",
+			addr1, "localhost:8080",
+			false, "invalid IP address: localhost",
+			addr1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := Struct(v, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestStructPtrIPNet(t *testing.T) {
+	_, net1, _ := net.ParseCIDR("10.0.0.0/8")
+	_, net2, _ := net.ParseCIDR("192.168.0.0/16")
+
+	tests := []struct {
+		name            string
+		s               *net.IPNet
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  *net.IPNet
+	}{
+		{
+			"Nil",
+			nil, "10.0.0.0/8",
+			true, "",
+			net1,
+		},
+		{
+			"NewValue",
+			net1, "192.168.0.0/16",
+			true, "",
+			net2,
+		},
+		{
+			"NoNewValue",
+			net2, "192.168.0.0/16",
+			false, "",
+			net2,
+		},
+		{
+			"InvalidValue",
+			net1, "10.0.0.0/33",
+			false, "invalid CIDR address: 10.0.0.0/33",
+			net1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := StructPtr(v, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestStructPtrTCPAddr(t *testing.T) {
+	addr1 := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8080}
+	addr2 := &net.TCPAddr{IP: net.ParseIP("::1"), Port: 9090}
+
+	tests := []struct {
+		name            string
+		s               *net.TCPAddr
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  *net.TCPAddr
+	}{
+		{
+			"Nil",
+			nil, "127.0.0.1:8080",
+			true, "",
+			addr1,
+		},
+		{
+			"NewValue",
+			addr1, "[::1]:9090",
+			true, "",
+			addr2,
+		},
+		{
+			"NoNewValue",
+			addr2, "[::1]:9090",
+			false, "",
+			addr2,
+		},
+		{
+			"InvalidValue",
+			addr1, "127.0.0.1:65536",
+			false, "invalid port: 65536",
+			addr1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := StructPtr(v, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestStructSliceIPNet(t *testing.T) {
+	_, net1, _ := net.ParseCIDR("10.0.0.0/8")
+	_, net2, _ := net.ParseCIDR("192.168.0.0/16")
+
+	tests := []struct {
+		name            string
+		s               []net.IPNet
+		vals            []string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  []net.IPNet
+	}{
+		{
+			"NewValue",
+			[]net.IPNet{}, []string{"10.0.0.0/8", "192.168.0.0/16"},
+			true, "",
+			[]net.IPNet{*net1, *net2},
+		},
+		{
+			"NoNewValue",
+			[]net.IPNet{*net1, *net2}, []string{"10.0.0.0/8", "192.168.0.0/16"},
+			false, "",
+			[]net.IPNet{*net1, *net2},
+		},
+		{
+			"InvalidValue",
+			[]net.IPNet{}, []string{"10.0.0.0/8", "192.168.0.0"},
+			false, "invalid CIDR address: 192.168.0.0",
+			[]net.IPNet{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := StructSlice(v, tc.vals)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestStructSliceTCPAddr(t *testing.T) {
+	addr1 := net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8080}
+	addr2 := net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 8080}
+
+	tests := []struct {
+		name            string
+		s               []net.TCPAddr
+		vals            []string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  []net.TCPAddr
+	}{
+		{
+			"NewValue",
+			[]net.TCPAddr{}, []string{"10.0.0.1:8080", "10.0.0.2:8080"},
+			true, "",
+			[]net.TCPAddr{addr1, addr2},
+		},
+		{
+			"NoNewValue",
+			[]net.TCPAddr{addr1, addr2}, []string{"10.0.0.1:8080", "10.0.0.2:8080"},
+			false, "",
+			[]net.TCPAddr{addr1, addr2},
+		},
+		{
+			"InvalidValue",
+			[]net.TCPAddr{}, []string{"10.0.0.1:8080", "db:5432"},
+			false, "invalid IP address: db",
+			[]net.TCPAddr{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := StructSlice(v, tc.vals)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestHardwareAddr(t *testing.T) {
+	mac1, _ := net.ParseMAC("00:00:5e:00:53:01")
+	mac2, _ := net.ParseMAC("00:00:5e:00:53:02")
+
+	tests := []struct {
+		name            string
+		s               net.HardwareAddr
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  net.HardwareAddr
+	}{
+		{
+			"NewValue",
+			mac1, "00-00-5e-00-53-02",
+			true, "",
+			mac2,
+		},
+		{
+			"NoNewValue",
+			mac2, "00:00:5e:00:53:02",
+			false, "",
+			mac2,
+		},
+		{
+			"InvalidValue",
+			mac1, "00:00:5e",
+			false, "address 00:00:5e: invalid MAC address",
+			mac1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := HardwareAddr(v, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestHardwareAddrPtr(t *testing.T) {
+	mac1, _ := net.ParseMAC("00:00:5e:00:53:01")
+	mac2, _ := net.ParseMAC("00:00:5e:00:53:02")
+
+	tests := []struct {
+		name            string
+		s               *net.HardwareAddr
+		val             string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  *net.HardwareAddr
+	}{
+		{
+			"Nil",
+			nil, "00:00:5e:00:53:01",
+			true, "",
+			&mac1,
+		},
+		{
+			"NewValue",
+			&mac1, "00:00:5e:00:53:02",
+			true, "",
+			&mac2,
+		},
+		{
+			"NoNewValue",
+			&mac2, "00:00:5e:00:53:02",
+			false, "",
+			&mac2,
+		},
+		{
+			"InvalidValue",
+			&mac1, "mac",
+			false, "address mac: invalid MAC address",
+			&mac1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := HardwareAddrPtr(v, tc.val)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
+
+func TestHardwareAddrSlice(t *testing.T) {
+	mac1, _ := net.ParseMAC("00:00:5e:00:53:01")
+	mac2, _ := net.ParseMAC("00:00:5e:00:53:02")
+
+	tests := []struct {
+		name            string
+		s               []net.HardwareAddr
+		vals            []string
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  []net.HardwareAddr
+	}{
+		{
+			"NewValue",
+			[]net.HardwareAddr{}, []string{"00:00:5e:00:53:01", "00:00:5e:00:53:02"},
+			true, "",
+			[]net.HardwareAddr{mac1, mac2},
+		},
+		{
+			"NoNewValue",
+			[]net.HardwareAddr{mac1, mac2}, []string{"00:00:5e:00:53:01", "00:00:5e:00:53:02"},
+			false, "",
+			[]net.HardwareAddr{mac1, mac2},
+		},
+		{
+			"InvalidValue",
+			[]net.HardwareAddr{}, []string{"00:00:5e:00:53:01", "mac"},
+			false, "address mac: invalid MAC address",
+			[]net.HardwareAddr{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(&tc.s).Elem()
+			updated, err := HardwareAddrSlice(v, tc.vals)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package set
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// UnitBytes is the unit for byte sizes.
+	// The sizes can have decimal suffixes (k, M, G, T, P, E) and binary suffixes (Ki, Mi, Gi, Ti, Pi, Ei),
+	// optionally followed by B (512KiB, 10MB, 1.5G, or 100B).
+	UnitBytes = "bytes"
+	// UnitSI is the unit for counts with SI suffixes (k, M, G, T, P, E), such as 10k or 1.5M.
+	UnitSI = "si"
+)
+
+type unitSuffix struct {
+	name string
+	mult uint64
+}
+
+var (
+	binarySuffixes = []unitSuffix{
+		{"Ei", 1 << 60}, {"Pi", 1 << 50}, {"Ti", 1 << 40}, {"Gi", 1 << 30}, {"Mi", 1 << 20}, {"Ki", 1 << 10},
+	}
+
+	decimalSuffixes = []unitSuffix{
+		{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"k", 1e3},
+	}
+)
+
+// Unit sets the unit for parsing integer values with suffixes (UnitBytes or UnitSI).
+// Without a unit, integer values cannot have any suffix.
+// The values with a base prefix (0x400) are parsed as plain integers and cannot have a suffix either.
+func Unit(unit string) Option {
+	return func(o *options) {
+		o.unit = unit
+	}
+}
+
+// IsUnitSupported determines whether or not a unit can be used for a type.
+// The units are supported for integer types (except time.Duration and os.FileMode), and pointers to and slices of them.
+func IsUnitSupported(unit string, t reflect.Type) bool {
+	if unit != UnitBytes && unit != UnitSI {
+		return false
+	}
+
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	return isInteger(t)
+}
+
+// isInteger determines whether or not a type is an integer type other than time.Duration and os.FileMode.
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return t != reflect.TypeOf(time.Duration(0)) && !isFileModeType(t)
+	default:
+		return false
+	}
+}
+
+// multiplier returns the multiplier for a suffix in a unit.
+// The suffixes are case-insensitive.
+func multiplier(unit, suffix string) (uint64, bool) {
+	s := strings.ToLower(suffix)
+	if unit == UnitBytes {
+		if s == "b" {
+			return 1, true
+		}
+		s = strings.TrimSuffix(s, "b")
+	}
+
+	for _, u := range decimalSuffixes {
+		if s == strings.ToLower(u.name) {
+			return u.mult, true
+		}
+	}
+
+	if unit == UnitBytes {
+		for _, u := range binarySuffixes {
+			if s == strings.ToLower(u.name) {
+				return u.mult, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// applyUnit converts an integer value with a suffix to a plain integer value.
+// A value without any suffix or with a base prefix (0x400) is returned as is,
+// since the digits of the other bases could be mistaken for suffixes.
+func applyUnit(val, unit string) (string, error) {
+	i := strings.IndexFunc(val, unicode.IsLetter)
+	if unit == "" || i < 0 || hasBasePrefix(val) {
+		return val, nil
+	}
+
+	num, suffix := strings.TrimSpace(val[:i]), val[i:]
+
+	mult, ok := multiplier(unit, suffix)
+	if !ok {
+		return "", fmt.Errorf("invalid unit in value %q: %s", val, suffix)
+	}
+
+	sign := ""
+	if strings.HasPrefix(num, "-") || strings.HasPrefix(num, "+") {
+		sign, num = num[:1], num[1:]
+		if sign == "+" {
+			sign = ""
+		}
+	}
+
+	var n uint64
+
+	if strings.Contains(num, ".") {
+		if _, err := strconv.ParseFloat(num, 64); err != nil {
+			return "", err
+		}
+
+		// The value is scaled exactly, since floating-point rounding would leave a fraction (4.1G)
+		r, ok := new(big.Rat).SetString(num)
+		if !ok {
+			return "", &strconv.NumError{Func: "ParseFloat", Num: num, Err: strconv.ErrSyntax}
+		}
+
+		r.Mul(r, new(big.Rat).SetInt(new(big.Int).SetUint64(mult)))
+		if !r.IsInt() {
+			return "", fmt.Errorf("invalid value %q: not a whole number", val)
+		}
+		if !r.Num().IsUint64() {
+			return "", fmt.Errorf("value out of range: %s", val)
+		}
+
+		n = r.Num().Uint64()
+	} else {
+		// Underscores are allowed between the digits (1_024KiB)
+		s, base := intLiteral(num, 0)
+		u, err := strconv.ParseUint(s, base, 64)
+		if err != nil {
+			return "", err
+		}
+
+		if u > math.MaxUint64/mult {
+			return "", fmt.Errorf("value out of range: %s", val)
+		}
+
+		n = u * mult
+	}
+
+	if n == 0 {
+		sign = ""
+	}
+
+	return sign + strconv.FormatUint(n, 10), nil
+}
+
+// FormatUnit formats an integer value or a pointer to an integer value using the largest suffix of a unit
+// that represents it exactly with at most two decimal places (1500 is formatted as 1.5k).
+// For UnitBytes, the binary suffixes are preferred over the decimal ones (1048576 is formatted as 1MiB and 1000000 as 1MB).
+// The other values are formatted as the plain values.
+func FormatUnit(v reflect.Value, unit string) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		v = v.Elem()
+	}
+
+	var sign string
+	var n uint64
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		n = uint64(i)
+		if i < 0 {
+			sign, n = "-", uint64(-i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = v.Uint()
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+
+	if n == 0 {
+		return "0"
+	}
+
+	var suffixes []unitSuffix
+	var b string
+
+	switch unit {
+	case UnitBytes:
+		suffixes, b = append(append(suffixes, binarySuffixes...), decimalSuffixes...), "B"
+	case UnitSI:
+		suffixes = decimalSuffixes
+	}
+
+	for _, u := range suffixes {
+		if n < u.mult {
+			continue
+		}
+
+		q, r := n/u.mult, n%u.mult
+		if r == 0 {
+			return sign + strconv.FormatUint(q, 10) + u.name + b
+		}
+
+		// Up to two decimal places are used if the value can be represented exactly
+		if u.mult <= math.MaxUint64/100 && (r*100)%u.mult == 0 {
+			frac := strings.TrimRight(fmt.Sprintf("%02d", r*100/u.mult), "0")
+			return sign + strconv.FormatUint(q, 10) + "." + frac + u.name + b
+		}
+	}
+
+	return sign + strconv.FormatUint(n, 10)
+}
@@ -0,0 +1,209 @@
+package set
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUnitSupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		unit     string
+		value    interface{}
+		expected bool
+	}{
+		{"Bytes_Int", UnitBytes, int(0), true},
+		{"Bytes_Uint64", UnitBytes, uint64(0), true},
+		{"Bytes_IntPointer", UnitBytes, new(int), true},
+		{"Bytes_IntSlice", UnitBytes, []int{}, true},
+		{"SI_Int32", UnitSI, int32(0), true},
+		{"Bytes_Duration", UnitBytes, time.Duration(0), false},
+		{"Bytes_FileMode", UnitBytes, os.FileMode(0), false},
+		{"Bytes_Float64", UnitBytes, float64(0), false},
+		{"Bytes_String", UnitBytes, "", false},
+		{"Invalid", "bits", int(0), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsUnitSupported(tc.unit, reflect.TypeOf(tc.value)))
+		})
+	}
+}
+
+func TestApplyUnit(t *testing.T) {
+	tests := []struct {
+		name          string
+		val           string
+		unit          string
+		expectedError string
+		expectedVal   string
+	}{
+		{"NoUnit", "10MB", "", "", "10MB"},
+		{"NoSuffix", "1048576", UnitBytes, "", "1048576"},
+		{"Bytes_B", "100B", UnitBytes, "", "100"},
+		{"Bytes_KiB", "512KiB", UnitBytes, "", "524288"},
+		{"Bytes_Ki", "512Ki", UnitBytes, "", "524288"},
+		{"Bytes_MB", "10MB", UnitBytes, "", "10000000"},
+		{"Bytes_G", "1.5G", UnitBytes, "", "1500000000"},
+		{"Bytes_GiB", "1.5GiB", UnitBytes, "", "1610612736"},
+		{"Bytes_DecimalG", "4.1G", UnitBytes, "", "4100000000"},
+		{"Bytes_DecimalGB", "8.3GB", UnitBytes, "", "8300000000"},
+		{"Bytes_LowerCase", "2mib", UnitBytes, "", "2097152"},
+		{"Bytes_Space", "64 KiB", UnitBytes, "", "65536"},
+		{"Bytes_EiB", "15EiB", UnitBytes, "", "17293822569102704640"},
+		{"SI_k", "10k", UnitSI, "", "10000"},
+		{"SI_M", "1.5M", UnitSI, "", "1500000"},
+		{"SI_Negative", "-2k", UnitSI, "", "-2000"},
+		{"Bytes_Underscores", "1_024KiB", UnitBytes, "", "1048576"},
+		{"Bytes_Hex", "0x10", UnitBytes, "", "0x10"},
+		{"SI_NegativeHex", "-0x1F", UnitSI, "", "-0x1F"},
+		{"SI_B", "10kB", UnitSI, `invalid unit in value "10kB": kB`, ""},
+		{"InvalidSuffix", "10XB", UnitBytes, `invalid unit in value "10XB": XB`, ""},
+		{"InvalidNumber", "1..5M", UnitSI, `strconv.ParseFloat: parsing "1..5": invalid syntax`, ""},
+		{"NotWholeNumber", "1.5B", UnitBytes, `invalid value "1.5B": not a whole number`, ""},
+		{"OutOfRange", "16EiB", UnitBytes, "value out of range: 16EiB", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			val, err := applyUnit(tc.val, tc.unit)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedVal, val)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestFormatUnit(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		unit     string
+		expected string
+	}{
+		{"Zero", int(0), UnitBytes, "0"},
+		{"Bytes_Binary", int(1048576), UnitBytes, "1MiB"},
+		{"Bytes_Decimal", int64(10000000), UnitBytes, "10MB"},
+		{"Bytes_BinaryFraction", int(1536), UnitBytes, "1.5KiB"},
+		{"Bytes_DecimalFraction", int(1500000), UnitBytes, "1.5MB"},
+		{"Bytes_Plain", uint(1001), UnitBytes, "1001"},
+		{"Bytes_Small", uint(512), UnitBytes, "512"},
+		{"Bytes_Negative", int(-2048), UnitBytes, "-2KiB"},
+		{"Bytes_Pointer", func() *int { i := 4096; return &i }(), UnitBytes, "4KiB"},
+		{"Bytes_NilPointer", (*int)(nil), UnitBytes, "<nil>"},
+		{"SI_k", uint32(1000), UnitSI, "1k"},
+		{"SI_M", int(2000000), UnitSI, "2M"},
+		{"SI_Fraction", int(1250), UnitSI, "1.25k"},
+		{"SI_Plain", int(10001), UnitSI, "10001"},
+		{"NotInteger", "10", UnitSI, "10"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatUnit(reflect.ValueOf(tc.value), tc.unit))
+		})
+	}
+}
+
+func TestValueUnit(t *testing.T) {
+	tests := []struct {
+		name            string
+		s               interface{}
+		sep             string
+		val             string
+		opts            []Option
+		expectedUpdated bool
+		expectedError   string
+		expectedResult  interface{}
+	}{
+		{
+			"Int",
+			new(int),
+			"", "512KiB",
+			[]Option{Unit(UnitBytes)},
+			true, "",
+			func() *int { i := 524288; return &i }(),
+		},
+		{
+			"IntPointer",
+			new(*uint64),
+			"", "1.5G",
+			[]Option{Unit(UnitBytes)},
+			true, "",
+			func() **uint64 { u := uint64(1500000000); p := &u; return &p }(),
+		},
+		{
+			"Hex",
+			new(int64),
+			"", "0x10",
+			[]Option{Unit(UnitBytes)},
+			true, "",
+			func() *int64 { i := int64(16); return &i }(),
+		},
+		{
+			"Underscores",
+			new(uint),
+			"", "1_024KiB",
+			[]Option{Unit(UnitBytes)},
+			true, "",
+			func() *uint { u := uint(1048576); return &u }(),
+		},
+		{
+			"IntSlice",
+			new([]int32),
+			",", "1k,2.5k,3000",
+			[]Option{Unit(UnitSI)},
+			true, "",
+			&[]int32{1000, 2500, 3000},
+		},
+		{
+			"IntMap",
+			new(map[string]int),
+			",", "cache=64MiB",
+			[]Option{Unit(UnitBytes)},
+			true, "",
+			&map[string]int{"cache": 67108864},
+		},
+		{
+			"OutOfRange",
+			new(int8),
+			"", "1k",
+			[]Option{Unit(UnitSI)},
+			false, `strconv.ParseInt: parsing "1000": value out of range`,
+			new(int8),
+		},
+		{
+			"NoUnit",
+			new(int),
+			"", "10MB",
+			nil,
+			false, `strconv.ParseInt: parsing "10MB": invalid syntax`,
+			new(int),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.s).Elem()
+			updated, err := Value(v, tc.sep, tc.val, tc.opts...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+
+			assert.Equal(t, tc.expectedUpdated, updated)
+			assert.Equal(t, tc.expectedResult, tc.s)
+		})
+	}
+}
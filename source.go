@@ -0,0 +1,243 @@
+package flagit
+
+import (
+	"fmt"
+	"os"
+)
+
+// Source is a source of flag values, such as a config file, environment variables, or command-line arguments.
+type Source interface {
+	// Lookup returns the value of a flag by its full name (including the prefixes of nested structs)
+	// and whether or not the flag has a value in the source.
+	Lookup(name string) (string, bool)
+}
+
+// MapSource is a source of flag values backed by a map from flag names to values.
+// It can be used for the values read from a config file.
+type MapSource map[string]string
+
+// Lookup returns the value of a flag from the map.
+func (m MapSource) Lookup(name string) (string, bool) {
+	val, ok := m[name]
+	return val, ok
+}
+
+// Origin returns the origin of the values from the map.
+func (m MapSource) Origin() Origin {
+	return OriginFile
+}
+
+// originer is implemented by the sources that know where their values come from.
+type originer interface {
+	Origin() Origin
+}
+
+// fieldLoader is implemented by the sources that need to know about the fields rather than only their flag names.
+type fieldLoader interface {
+	// load assigns the values from the source to the fields and records them in the report.
+	// It returns the names of the flags that have a value in the source.
+	load(fields []fieldInfo, r *Report) (map[string]bool, error)
+}
+
+// loadFields assigns the values from a source to the fields and records them in the report.
+// It returns the names of the flags that have a value in the source.
+func loadFields(src Source, fields []fieldInfo, r *Report) (map[string]bool, error) {
+	if l, ok := src.(fieldLoader); ok {
+		return l.load(fields, r)
+	}
+
+	origin := OriginSource
+	if o, ok := src.(originer); ok {
+		origin = o.Origin()
+	}
+
+	seen := map[string]bool{}
+
+	for _, f := range fields {
+		val, ok := src.Lookup(f.flag)
+		if !ok {
+			continue
+		}
+
+		if _, err := f.setValue(val, false); err != nil {
+			return nil, fmt.Errorf("invalid value %q for flag %s: %s", val, f.flag, err)
+		}
+
+		r.set(f, origin, val, false)
+		seen[f.flag] = true
+	}
+
+	return seen, nil
+}
+
+type envSource struct {
+	prefix string
+}
+
+// Env returns a source that reads the flag values from the environment variables.
+// The environment variable of a flag is either the value of its env tag or derived from the flag name using the given prefix (see EnvPrefix).
+func Env(prefix string) Source {
+	return &envSource{
+		prefix: prefix,
+	}
+}
+
+// Lookup returns the value of the environment variable derived from the flag name.
+func (s *envSource) Lookup(name string) (string, bool) {
+	return os.LookupEnv(envName(s.prefix, name))
+}
+
+// Origin returns the origin of the values from the environment variables.
+func (s *envSource) Origin() Origin {
+	return OriginEnv
+}
+
+func (s *envSource) load(fields []fieldInfo, r *Report) (map[string]bool, error) {
+	seen := map[string]bool{}
+
+	for _, f := range fields {
+		if f.env == "" {
+			f.env = envName(s.prefix, f.flag)
+		}
+
+		ok, err := f.setEnv()
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			r.set(f, OriginEnv, os.Getenv(f.env), false)
+			seen[f.flag] = true
+		}
+	}
+
+	return seen, nil
+}
+
+type argsSource struct {
+	args []string
+}
+
+// Args returns a source that reads the flag values from the given command-line arguments.
+// The arguments should not include the program name (similar to os.Args[1:]).
+// The command-line syntax is the same as PopulateArgs, but the positional arguments and subcommands are ignored.
+func Args(args []string) Source {
+	return &argsSource{
+		args: args,
+	}
+}
+
+// Lookup returns the value of the last occurrence of a flag in the arguments.
+// Since the type of the flag is unknown, a flag without any value is assumed to be a boolean flag and its value is true.
+func (s *argsSource) Lookup(name string) (string, bool) {
+	var val string
+	var found bool
+
+	for i := 0; i < len(s.args); i++ {
+		arg := s.args[i]
+		if arg == "--" {
+			break
+		}
+
+		a, isFlag := parseFlagArg(arg)
+		if !isFlag || a.name != name {
+			continue
+		}
+
+		switch {
+		case a.hasValue:
+			val = a.value
+		case i+1 < len(s.args) && isValueArg(s.args[i+1]):
+			i++
+			val = s.args[i]
+		default:
+			val = "true"
+		}
+		found = true
+	}
+
+	return val, found
+}
+
+// Origin returns the origin of the values from the command-line arguments.
+func (s *argsSource) Origin() Origin {
+	return OriginFlag
+}
+
+func (s *argsSource) load(fields []fieldInfo, r *Report) (map[string]bool, error) {
+	p := newParser(false)
+	p.options.report = r
+
+	for _, f := range fields {
+		if err := p.add(f); err != nil {
+			return nil, err
+		}
+	}
+
+	_, seen, err := p.populate(s.args)
+	if err != nil {
+		return nil, err
+	}
+
+	return seen, nil
+}
+
+// Load accepts the pointer to a struct type and resolves the struct fields that have the flag tag from the given sources.
+// The struct fields with the zero value are first set to the values of their default tags.
+// Then, the sources are read in order and the values from each source replace the values from the previous ones.
+// For example, the sources can be a config file (MapSource), the environment variables (Env), and the command-line arguments (Args).
+// Once all sources are read, an error is returned if any required flag has not been resolved or any value is not valid (see Validate).
+func Load(s interface{}, sources ...Source) error {
+	_, err := LoadReport(s, sources...)
+	return err
+}
+
+// LoadReport is the same as Load, but it also returns a report of where the value of each struct field came from.
+func LoadReport(s interface{}, sources ...Source) (*Report, error) {
+	v, err := validateStruct(s)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{}
+	fields := []fieldInfo{}
+
+	err = iterateOnFields("", v, false, func(f fieldInfo) error {
+		r.add(f)
+		fields = append(fields, f)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		if ok, _ := f.setDefault(); ok {
+			r.set(f, OriginDefault, f.def, false)
+		}
+	}
+
+	seen := map[string]bool{}
+
+	for _, src := range sources {
+		names, err := loadFields(src, fields, r)
+		if err != nil {
+			return nil, err
+		}
+
+		for name := range names {
+			seen[name] = true
+		}
+	}
+
+	if err := checkRequired(fields, seen); err != nil {
+		return nil, err
+	}
+
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
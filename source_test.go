@@ -0,0 +1,250 @@
+package flagit
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sourceFunc func(name string) (string, bool)
+
+func (f sourceFunc) Lookup(name string) (string, bool) {
+	return f(name)
+}
+
+func TestMapSource(t *testing.T) {
+	src := MapSource{"log.level": "debug"}
+
+	val, ok := src.Lookup("log.level")
+	assert.True(t, ok)
+	assert.Equal(t, "debug", val)
+
+	val, ok = src.Lookup("port")
+	assert.False(t, ok)
+	assert.Equal(t, "", val)
+}
+
+func TestEnvLookup(t *testing.T) {
+	os.Setenv("APP_LOG_LEVEL", "debug")
+	defer os.Unsetenv("APP_LOG_LEVEL")
+
+	src := Env("APP")
+
+	val, ok := src.Lookup("log.level")
+	assert.True(t, ok)
+	assert.Equal(t, "debug", val)
+
+	val, ok = src.Lookup("port")
+	assert.False(t, ok)
+	assert.Equal(t, "", val)
+}
+
+func TestArgsLookup(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		flag          string
+		expectedValue string
+		expectedOK    bool
+	}{
+		{"Missing", []string{"-port", "8080"}, "log.level", "", false},
+		{"EqualSign", []string{"--log.level=debug"}, "log.level", "debug", true},
+		{"NextArg", []string{"-log.level", "debug"}, "log.level", "debug", true},
+		{"LastOccurrence", []string{"-log.level", "debug", "--log.level=info"}, "log.level", "info", true},
+		{"Boolean", []string{"-debug", "-port", "8080"}, "debug", "true", true},
+		{"Terminator", []string{"--", "-debug"}, "debug", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			val, ok := Args(tc.args).Lookup(tc.flag)
+
+			assert.Equal(t, tc.expectedValue, val)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	type spec struct {
+		Debug bool `flag:"debug" short:"d"`
+		Port  int  `flag:"port" env:"PORT" default:"80"`
+		Log   struct {
+			Level string `flag:"level" default:"info"`
+		} `flag:"log."`
+		Timeout time.Duration `flag:"timeout"`
+		Tags    []string      `flag:"tag" mode:"append"`
+		Token   string        `flag:"token" required:"true"`
+	}
+
+	os.Setenv("PORT", "8080")
+	defer os.Unsetenv("PORT")
+
+	os.Setenv("APP_TIMEOUT", "10s")
+	defer os.Unsetenv("APP_TIMEOUT")
+
+	tests := []struct {
+		name          string
+		s             interface{}
+		sources       []Source
+		expectedError string
+		expected      interface{}
+	}{
+		{
+			name:          "NonPointer",
+			s:             spec{},
+			expectedError: "non-pointer type: you should pass a pointer to a struct type",
+		},
+		{
+			name: "InvalidFlag",
+			s: &struct {
+				Port int `flag:"port number"`
+			}{},
+			expectedError: "invalid flag name: port number",
+		},
+		{
+			name: "DefaultsOnly",
+			s:    &spec{Token: "secret"},
+			expected: &spec{
+				Port:  80,
+				Token: "secret",
+				Log: struct {
+					Level string `flag:"level" default:"info"`
+				}{Level: "info"},
+			},
+		},
+		{
+			name: "Precedence",
+			s:    &spec{},
+			sources: []Source{
+				MapSource{"port": "9000", "timeout": "1s", "log.level": "warn", "token": "file"},
+				Env("APP"),
+				Args([]string{"-d", "--log.level", "debug", "--tag", "a", "--tag=b", "input.txt"}),
+			},
+			expected: &spec{
+				Debug:   true,
+				Port:    8080,
+				Timeout: 10 * time.Second,
+				Tags:    []string{"a", "b"},
+				Token:   "file",
+				Log: struct {
+					Level string `flag:"level" default:"info"`
+				}{Level: "debug"},
+			},
+		},
+		{
+			name: "CustomSource",
+			s:    &spec{},
+			sources: []Source{
+				sourceFunc(func(name string) (string, bool) {
+					if name == "token" {
+						return "custom", true
+					}
+					return "", false
+				}),
+			},
+			expected: &spec{
+				Port:  80,
+				Token: "custom",
+				Log: struct {
+					Level string `flag:"level" default:"info"`
+				}{Level: "info"},
+			},
+		},
+		{
+			name: "InvalidValue",
+			s:    &spec{},
+			sources: []Source{
+				MapSource{"port": "http"},
+			},
+			expectedError: `invalid value "http" for flag port: strconv.ParseInt: parsing "http": invalid syntax`,
+		},
+		{
+			name: "InvalidArgs",
+			s:    &spec{},
+			sources: []Source{
+				Args([]string{"--unknown"}),
+			},
+			expectedError: "flag provided but not defined: --unknown",
+		},
+		{
+			name: "MissingRequired",
+			s:    &spec{},
+			sources: []Source{
+				MapSource{"port": "9000"},
+			},
+			expectedError: "missing required flags: token",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Load(tc.s, tc.sources...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, tc.s)
+			} else {
+				assert.Equal(t, errors.New(tc.expectedError), err)
+			}
+		})
+	}
+}
+
+func TestLoadReport(t *testing.T) {
+	type spec struct {
+		Port    int           `flag:"port" default:"80"`
+		Timeout time.Duration `flag:"timeout"`
+		Debug   bool          `flag:"debug"`
+		Name    string        `flag:"name"`
+		Region  string        `flag:"region"`
+	}
+
+	os.Setenv("APP_TIMEOUT", "10s")
+	defer os.Unsetenv("APP_TIMEOUT")
+
+	custom := sourceFunc(func(name string) (string, bool) {
+		if name == "name" {
+			return "custom", true
+		}
+		return "", false
+	})
+
+	s := &spec{}
+	r, err := LoadReport(s,
+		MapSource{"port": "9000", "timeout": "1s"},
+		Env("APP"),
+		custom,
+		Args([]string{"--debug"}),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &spec{Port: 9000, Timeout: 10 * time.Second, Debug: true, Name: "custom"}, s)
+
+	expected := []struct {
+		flag   string
+		origin Origin
+		raw    []string
+	}{
+		{"port", OriginFile, []string{"9000"}},
+		{"timeout", OriginEnv, []string{"10s"}},
+		{"debug", OriginFlag, []string{"true"}},
+		{"name", OriginSource, []string{"custom"}},
+		{"region", OriginNone, []string{}},
+	}
+
+	entries := r.Entries()
+	assert.Len(t, entries, len(expected))
+
+	for i, exp := range expected {
+		assert.Equal(t, exp.flag, entries[i].Flag)
+		assert.Equal(t, exp.origin, entries[i].Origin, exp.flag)
+		assert.Equal(t, exp.raw, entries[i].Raw, exp.flag)
+	}
+
+	_, err = LoadReport(spec{})
+	assert.EqualError(t, err, "non-pointer type: you should pass a pointer to a struct type")
+}
@@ -0,0 +1,344 @@
+package flagit
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/moorara/flagit/set"
+)
+
+const (
+	minTag      = "min"
+	maxTag      = "max"
+	oneofTag    = "oneof"
+	patternTag  = "pattern"
+	minLenTag   = "minlen"
+	maxLenTag   = "maxlen"
+	nonEmptyTag = "nonempty"
+)
+
+// ruleTags are the validation tags in the order they are checked.
+var ruleTags = []string{nonEmptyTag, minTag, maxTag, oneofTag, patternTag, minLenTag, maxLenTag}
+
+// ValidationError is returned when the value of a flag violates a validation rule.
+type ValidationError struct {
+	// Flag is the full name of the flag (including the prefixes of nested structs).
+	Flag string
+	// Rule is the name of the violated rule (the validation tag).
+	Rule string
+	// Param is the parameter of the violated rule (the value of the validation tag).
+	Param string
+	// Value is the value of the flag.
+	Value interface{}
+}
+
+func (e *ValidationError) Error() string {
+	var msg string
+	switch e.Rule {
+	case nonEmptyTag:
+		msg = "must not be empty"
+	case minTag:
+		msg = "must be at least " + e.Param
+	case maxTag:
+		msg = "must be at most " + e.Param
+	case oneofTag:
+		msg = "must be one of " + e.Param
+	case patternTag:
+		msg = "must match " + e.Param
+	case minLenTag:
+		msg = "length must be at least " + e.Param
+	case maxLenTag:
+		msg = "length must be at most " + e.Param
+	default:
+		msg = fmt.Sprintf("violates %s=%s", e.Rule, e.Param)
+	}
+
+	return fmt.Sprintf("invalid value %v for flag %s: %s", formatValue(reflect.ValueOf(e.Value)), e.Flag, msg)
+}
+
+// rule is a validation rule for the value of a field.
+type rule struct {
+	name  string
+	param string
+	check func(v reflect.Value) bool
+}
+
+// String returns the rule in the same form as its tag.
+func (r rule) String() string {
+	return r.name + "=" + r.param
+}
+
+// parseRules creates the validation rules from the validation tags of a struct field.
+func parseRules(tag reflect.StructTag, t reflect.Type) ([]rule, error) {
+	rules := []rule{}
+
+	// The base is already validated
+	base, _ := strconv.Atoi(tag.Get(baseTag))
+
+	for _, name := range ruleTags {
+		param, ok := tag.Lookup(name)
+		if !ok {
+			continue
+		}
+
+		check, err := newCheck(name, param, t, set.Unit(tag.Get(unitTag)), set.Base(base))
+		if err != nil {
+			return nil, fmt.Errorf("%s=%s", name, param)
+		}
+
+		if check != nil {
+			rules = append(rules, rule{
+				name:  name,
+				param: param,
+				check: check,
+			})
+		}
+	}
+
+	return rules, nil
+}
+
+// newCheck creates the function for checking a validation rule on the values of a given type.
+// It returns nil if the rule does not need to be checked.
+// The options are used for parsing the parameters of the rules.
+func newCheck(name, param string, t reflect.Type, opts ...set.Option) (func(reflect.Value) bool, error) {
+	if name == nonEmptyTag {
+		b, err := strconv.ParseBool(param)
+		if err != nil {
+			return nil, err
+		}
+		if !b {
+			return nil, nil
+		}
+
+		return func(v reflect.Value) bool {
+			switch v.Kind() {
+			case reflect.Slice, reflect.Map:
+				return v.Len() > 0
+			default:
+				return !v.IsZero()
+			}
+		}, nil
+	}
+
+	// The rules are checked on the values pointed to and the elements of slices
+	elem := t
+	if elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice {
+		elem = elem.Elem()
+	}
+
+	switch name {
+	case minTag, maxTag:
+		if !isNumber(elem) {
+			return nil, fmt.Errorf("unsupported type: %s", t)
+		}
+
+		bound := reflect.New(elem).Elem()
+		if _, err := set.Value(bound, "", param, opts...); err != nil {
+			return nil, err
+		}
+
+		return func(v reflect.Value) bool {
+			return forEach(v, func(e reflect.Value) bool {
+				if name == minTag {
+					return compare(e, bound) >= 0
+				}
+				return compare(e, bound) <= 0
+			})
+		}, nil
+
+	case oneofTag:
+		if !isScalarSupported(elem) {
+			return nil, fmt.Errorf("unsupported type: %s", t)
+		}
+
+		options := []reflect.Value{}
+		for _, opt := range strings.Split(param, ",") {
+			o := reflect.New(elem).Elem()
+			if _, err := set.Value(o, "", opt); err != nil {
+				return nil, err
+			}
+			options = append(options, o)
+		}
+
+		return func(v reflect.Value) bool {
+			return forEach(v, func(e reflect.Value) bool {
+				for _, o := range options {
+					if reflect.DeepEqual(e.Interface(), o.Interface()) {
+						return true
+					}
+				}
+				return false
+			})
+		}, nil
+
+	case patternTag:
+		if elem.Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported type: %s", t)
+		}
+
+		re, err := regexp.Compile(param)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(v reflect.Value) bool {
+			return forEach(v, func(e reflect.Value) bool {
+				return re.MatchString(e.String())
+			})
+		}, nil
+
+	case minLenTag, maxLenTag:
+		switch t.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map:
+		case reflect.Ptr:
+			if t.Elem().Kind() != reflect.String {
+				return nil, fmt.Errorf("unsupported type: %s", t)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported type: %s", t)
+		}
+
+		bound, err := strconv.Atoi(param)
+		if err != nil || bound < 0 {
+			return nil, fmt.Errorf("invalid length: %s", param)
+		}
+
+		return func(v reflect.Value) bool {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return true
+				}
+				v = v.Elem()
+			}
+
+			l := v.Len()
+			if v.Kind() == reflect.String {
+				l = utf8.RuneCountInString(v.String())
+			}
+
+			if name == minLenTag {
+				return l >= bound
+			}
+			return l <= bound
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown rule: %s", name)
+}
+
+// forEach checks a value pointed to or all elements of a slice value.
+// A nil pointer is considered valid.
+func forEach(v reflect.Value, check func(reflect.Value) bool) bool {
+	switch v.Kind() {
+	case reflect.Ptr:
+		return v.IsNil() || check(v.Elem())
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if !check(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return check(v)
+	}
+}
+
+// isNumber determines whether or not a type is a numeric type.
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// compare compares two numeric values of the same type.
+func compare(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch x, y := a.Int(), b.Int(); {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch x, y := a.Uint(), b.Uint(); {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	case reflect.Float32, reflect.Float64:
+		switch x, y := a.Float(), b.Float(); {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// validate checks the value of the field against all of its validation rules.
+func (f fieldInfo) validate() error {
+	for _, r := range f.rules {
+		if !r.check(f.value) {
+			return &ValidationError{
+				Flag:  f.flag,
+				Rule:  r.name,
+				Param: r.param,
+				Value: f.value.Interface(),
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateFields checks the values of all fields against their validation rules.
+// It returns the first violation.
+func validateFields(fields []fieldInfo) error {
+	for _, f := range fields {
+		if err := f.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate accepts the pointer to a struct type and checks the values of the struct fields against their validation tags
+// (min, max, oneof, pattern, minlen, maxlen, and nonempty).
+// Populate, PopulateArgs, and Load validate the values automatically.
+// When using RegisterFlags, the flags set on the command-line are validated by the flag set,
+// but Validate should be called after parsing the flag set to validate the rest of the values.
+// The returned error is a *ValidationError for the first violated rule.
+func Validate(s interface{}) error {
+	v, err := validateStruct(s)
+	if err != nil {
+		return err
+	}
+
+	fields := []fieldInfo{}
+	err = iterateOnFields("", v, false, func(f fieldInfo) error {
+		fields = append(fields, f)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return validateFields(fields)
+}
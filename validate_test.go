@@ -0,0 +1,204 @@
+package flagit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moorara/flagit/ptr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           *ValidationError
+		expectedError string
+	}{
+		{"NonEmpty", &ValidationError{"name", "nonempty", "true", ""}, "invalid value  for flag name: must not be empty"},
+		{"Min", &ValidationError{"port", "min", "1", 0}, "invalid value 0 for flag port: must be at least 1"},
+		{"Max", &ValidationError{"timeout", "max", "1m", 2 * time.Minute}, "invalid value 2m0s for flag timeout: must be at most 1m"},
+		{"OneOf", &ValidationError{"log.level", "oneof", "debug,info", "trace"}, "invalid value trace for flag log.level: must be one of debug,info"},
+		{"Pattern", &ValidationError{"name", "pattern", "^[a-z]+$", "App"}, "invalid value App for flag name: must match ^[a-z]+$"},
+		{"MinLen", &ValidationError{"tags", "minlen", "2", []string{"a"}}, "invalid value [a] for flag tags: length must be at least 2"},
+		{"MaxLen", &ValidationError{"name", "maxlen", "3", ptr.String("abcd")}, "invalid value abcd for flag name: length must be at most 3"},
+		{"Unknown", &ValidationError{"name", "custom", "x", "abc"}, "invalid value abc for flag name: violates custom=x"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.err, tc.expectedError)
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		tag           reflect.StructTag
+		value         interface{}
+		expectedError error
+		expectedRules []string
+	}{
+		{"NoRule", `flag:"port"`, new(int), nil, []string{}},
+		{"NonEmpty", `nonempty:"true"`, new(string), nil, []string{"nonempty=true"}},
+		{"NonEmpty_False", `nonempty:"false"`, new(string), nil, []string{}},
+		{"NonEmpty_Invalid", `nonempty:"yes"`, new(string), errors.New("nonempty=yes"), nil},
+		{"MinMax", `max:"65535" min:"1"`, new(uint16), nil, []string{"min=1", "max=65535"}},
+		{"MinMax_Duration", `min:"1s" max:"1m"`, new(time.Duration), nil, []string{"min=1s", "max=1m"}},
+		{"MinMax_Slice", `min:"0.5"`, new([]float64), nil, []string{"min=0.5"}},
+		{"MinMax_Pointer", `max:"10"`, new(*int), nil, []string{"max=10"}},
+		{"MinMax_InvalidType", `min:"1"`, new(string), errors.New("min=1"), nil},
+		{"MinMax_InvalidBound", `max:"many"`, new(int), errors.New("max=many"), nil},
+		{"OneOf", `oneof:"debug,info,warn"`, new(string), nil, []string{"oneof=debug,info,warn"}},
+		{"OneOf_Int", `oneof:"1,2,4"`, new([]int), nil, []string{"oneof=1,2,4"}},
+		{"OneOf_InvalidType", `oneof:"a=1"`, new(map[string]int), errors.New("oneof=a=1"), nil},
+		{"OneOf_InvalidOption", `oneof:"1,two"`, new(int), errors.New("oneof=1,two"), nil},
+		{"Pattern", `pattern:"^[a-z]+$"`, new([]string), nil, []string{"pattern=^[a-z]+$"}},
+		{"Pattern_InvalidType", `pattern:"^[0-9]+$"`, new(int), errors.New("pattern=^[0-9]+$"), nil},
+		{"Pattern_InvalidRegexp", `pattern:"[a-z"`, new(string), errors.New("pattern=[a-z"), nil},
+		{"Length", `minlen:"1" maxlen:"8"`, new(map[string]string), nil, []string{"minlen=1", "maxlen=8"}},
+		{"Length_Pointer", `maxlen:"8"`, new(*string), nil, []string{"maxlen=8"}},
+		{"Length_InvalidType", `minlen:"1"`, new(int), errors.New("minlen=1"), nil},
+		{"Length_InvalidPointer", `minlen:"1"`, new(*int), errors.New("minlen=1"), nil},
+		{"Length_InvalidBound", `maxlen:"-1"`, new(string), errors.New("maxlen=-1"), nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			typ := reflect.TypeOf(tc.value).Elem()
+			rules, err := parseRules(tc.tag, typ)
+
+			assert.Equal(t, tc.expectedError, err)
+
+			if tc.expectedError == nil {
+				names := []string{}
+				for _, r := range rules {
+					names = append(names, r.String())
+				}
+				assert.Equal(t, tc.expectedRules, names)
+			}
+		})
+	}
+}
+
+func TestFieldInfoValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		tag          reflect.StructTag
+		value        interface{}
+		expectedRule string
+	}{
+		{"NonEmpty_String", `nonempty:"true"`, ptr.String(""), "nonempty"},
+		{"NonEmpty_Slice", `nonempty:"true"`, &[]string{}, "nonempty"},
+		{"NonEmpty_Map", `nonempty:"true"`, &map[string]string{}, "nonempty"},
+		{"NonEmpty_Pointer", `nonempty:"true"`, new(*int), "nonempty"},
+		{"NonEmpty_OK", `nonempty:"true"`, &[]string{"a"}, ""},
+		{"Min", `min:"1" max:"65535"`, ptr.Int(0), "min"},
+		{"Max", `min:"1" max:"65535"`, ptr.Int(65536), "max"},
+		{"MinMax_OK", `min:"1" max:"65535"`, ptr.Int(8080), ""},
+		{"Min_Uint", `min:"10"`, ptr.Uint(9), "min"},
+		{"Max_Float", `max:"0.5"`, ptr.Float64(0.75), "max"},
+		{"Max_Duration", `max:"1m"`, ptr.Duration(time.Hour), "max"},
+		{"Max_NilPointer", `max:"10"`, new(*int), ""},
+		{"Max_Pointer", `max:"10"`, func() interface{} { p := ptr.Int(11); return &p }(), "max"},
+		{"Min_Slice", `min:"1"`, &[]int{1, 0, 2}, "min"},
+		{"OneOf", `oneof:"debug,info"`, ptr.String("trace"), "oneof"},
+		{"OneOf_OK", `oneof:"debug,info"`, ptr.String("info"), ""},
+		{"OneOf_Slice", `oneof:"1,2,4"`, &[]int{1, 4, 3}, "oneof"},
+		{"Pattern", `pattern:"^[a-z]+$"`, ptr.String("App"), "pattern"},
+		{"Pattern_OK", `pattern:"^[a-z]+$"`, ptr.String("app"), ""},
+		{"MinLen", `minlen:"2"`, &[]string{"a"}, "minlen"},
+		{"MaxLen_String", `maxlen:"3"`, ptr.String("ábcd"), "maxlen"},
+		{"MaxLen_Runes", `maxlen:"3"`, ptr.String("ábc"), ""},
+		{"MaxLen_NilPointer", `maxlen:"3"`, new(*string), ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := reflect.ValueOf(tc.value).Elem()
+			rules, err := parseRules(tc.tag, v.Type())
+			assert.NoError(t, err)
+
+			f := fieldInfo{
+				value: v,
+				flag:  "flag",
+				rules: rules,
+			}
+
+			err = f.validate()
+
+			if tc.expectedRule == "" {
+				assert.NoError(t, err)
+			} else {
+				verr, ok := err.(*ValidationError)
+				assert.True(t, ok)
+				assert.Equal(t, "flag", verr.Flag)
+				assert.Equal(t, tc.expectedRule, verr.Rule)
+				assert.Equal(t, v.Interface(), verr.Value)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type spec struct {
+		Port int `flag:"port" min:"1" max:"65535"`
+		Log  struct {
+			Level string `flag:"level" oneof:"debug,info,warn,error"`
+		} `flag:"log."`
+	}
+
+	tests := []struct {
+		name          string
+		s             interface{}
+		expectedError error
+	}{
+		{
+			name:          "NonPointer",
+			s:             spec{},
+			expectedError: errors.New("non-pointer type: you should pass a pointer to a struct type"),
+		},
+		{
+			name: "InvalidRule",
+			s: &struct {
+				Port int `flag:"port" min:"one"`
+			}{},
+			expectedError: errors.New("invalid validation rule for flag port: min=one"),
+		},
+		{
+			name: "Invalid",
+			s: &spec{
+				Port: 8080,
+				Log: struct {
+					Level string `flag:"level" oneof:"debug,info,warn,error"`
+				}{Level: "trace"},
+			},
+			expectedError: &ValidationError{
+				Flag:  "log.level",
+				Rule:  "oneof",
+				Param: "debug,info,warn,error",
+				Value: "trace",
+			},
+		},
+		{
+			name: "OK",
+			s: &spec{
+				Port: 8080,
+				Log: struct {
+					Level string `flag:"level" oneof:"debug,info,warn,error"`
+				}{Level: "info"},
+			},
+			expectedError: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.s)
+
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}